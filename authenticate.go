@@ -1,17 +1,42 @@
 package gonedrive
 
 import (
-	"bytes"
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
-	"fmt"
+	"errors"
+	"html/template"
 	"io"
+	"net"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/pkg/browser"
 )
 
-const scope = "Files.ReadWrite offline_access"
+// How long CreateAccess waits for the user to finish signing in.
+const DefaultLoginTimeout = 5 * time.Minute
+
+const defaultSuccessPage = "you can close this window now thanks :)"
+const defaultFailurePage = "sign-in failed: {{.}}"
+
+// Settings for the browser based login flow.
+type BrowserLoginParams struct {
+	// HTML page shown in the browser once the user has signed in.
+	SuccessPage string
+
+	// HTML page shown in the browser if sign-in failed.
+	// This is parsed as a html/template, and executed with the error message.
+	FailurePage string
+
+	// Opens the sign-in page for the user.
+	// Defaults to opening the URL in the system browser.
+	OpenURL func(url string) error
+}
 
 // Main entrypoint for the GoneDrive package.
 // With an access token, you can do pretty much everything you want in the API.
@@ -20,142 +45,270 @@ const scope = "Files.ReadWrite offline_access"
 // It is recommended to store and refresh tokens, rather than generating
 // new tokens every time your program runs.
 func CreateAccess(clientID string, redirectURI string, refresh *GraphToken) (*GraphToken, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultLoginTimeout)
+	defer cancel()
+	return CreateAccessBrowser(ctx, clientID, redirectURI, refresh, BrowserLoginParams{})
+}
+
+// Same as CreateAccess, but with control over the browser login flow.
+//
+// A local server is started on the host, port and path of redirectURI,
+// to receive the authorization code. If redirectURI has no port, or port 0,
+// an ephemeral port is used instead. The redirect URI registered for the app
+// must allow this, which is the case for "http://localhost" URIs.
+//
+// If the user does not finish signing in before ctx is done,
+// the login is aborted and the context error is returned.
+func CreateAccessBrowser(ctx context.Context, clientID string, redirectURI string, refresh *GraphToken, params BrowserLoginParams) (*GraphToken, error) {
+	return createAccess(ctx, clientID, redirectURI, refresh, func(t *GraphToken) error {
+		return t.generateNew(ctx, params)
+	})
+}
+
+// Shared logic for the different login methods.
+// Tries refreshing the given token, and falls back to loginFn if that fails.
+func createAccess(ctx context.Context, clientID string, redirectURI string, refresh *GraphToken, loginFn func(t *GraphToken) error) (*GraphToken, error) {
 	gotToken := false
 	t := &GraphToken{
-		clientID:    clientID,
-		redirectURI: redirectURI,
+		ClientID:    clientID,
+		RedirectURI: redirectURI,
 	}
 
 	// Try refreshing existing token
 	if refresh != nil {
 		t = refresh
-		t.clientID = clientID
-		t.redirectURI = redirectURI
-		gotToken = t.refresh() == nil
+		t.ClientID = clientID
+		t.RedirectURI = redirectURI
+		gotToken = t.RefreshToken != "" && t.refresh(ctx) == nil
 	}
 
 	// Get new token
 	if !gotToken {
-		err := t.generateNew()
+		err := loginFn(t)
 		if err != nil {
 			return nil, err
 		}
 	}
 
+	// Persist new token
+	if err := t.save(); err != nil {
+		return nil, err
+	}
 	return t, nil
 }
 
-func (t *GraphToken) generateNew() error {
-	responseType := "code"
-	responseMode := "query"
-	state := "0"
-	err := browser.OpenURL(fmt.Sprintf(
-		"https://login.microsoftonline.com/consumers/oauth2/v2.0/authorize?client_id=%s&response_type=%s&redirect_uri=%s&response_mode=%s&scope=%s&state=%s",
-		t.clientID,
-		responseType,
-		t.redirectURI,
-		responseMode,
-		scope,
-		state,
-	))
+func (t *GraphToken) generateNew(ctx context.Context, params BrowserLoginParams) error {
+	if params.SuccessPage == "" {
+		params.SuccessPage = defaultSuccessPage
+	}
+	if params.FailurePage == "" {
+		params.FailurePage = defaultFailurePage
+	}
+	if params.OpenURL == nil {
+		params.OpenURL = browser.OpenURL
+	}
+	failurePage, err := template.New("failure").Parse(params.FailurePage)
 	if err != nil {
 		return err
 	}
 
-	//Make first contant
-	code := ""
-	server := &http.Server{}
-	server.Addr = ":8090"
-	mux := http.NewServeMux()
-	mux.HandleFunc(
-		"/auth",
-		func(w http.ResponseWriter, req *http.Request) {
-
-			msg := ""
-			defer func() {
-				err = nil
-				if msg != "" {
-					err = fmt.Errorf(msg)
-				}
-				w.Write([]byte("you can close this window now thanks :)"))
-				server.Shutdown(context.TODO())
-			}()
-
-			//Verify state
-			qs := req.URL.Query()
-			rstate, ok := qs["state"]
-			if !ok || len(rstate) != 1 || rstate[0] != state {
-				if !ok {
-					msg = "query does not contain 'state'"
-				} else if len(rstate) != 1 {
-					msg = "'state' query has more/less than 1 value"
-				} else if rstate[0] != state {
-					msg = "invalid 'state' value in query"
-				}
-				return
-			}
-
-			//Get access code
-			rcode, ok := qs["code"]
-			if !ok || len(rcode) != 1 {
-				if !ok {
-					msg = "query does not contain 'code'"
-				} else if len(rstate) != 1 {
-					msg = "'code' query has more/less than 1 value"
-				}
-				return
-			}
-			code = rcode[0]
-		},
-	)
-	server.Handler = mux
-	server.ListenAndServe()
+	// Generate state and PKCE values
+	state, err := randomString()
+	if err != nil {
+		return err
+	}
+	verifier, err := randomString()
 	if err != nil {
 		return err
 	}
+	challenge := sha256.Sum256([]byte(verifier))
 
-	//Request access token
-	grant := "authorization_code"
-	resp, err := http.Post(
-		"https://login.microsoftonline.com/consumers/oauth2/v2.0/token",
-		"application/x-www-form-urlencoded",
-		bytes.NewBufferString(fmt.Sprintf(
-			"client_id=%s&scope=%s&code=%s&redirect_uri=%s&grant_type=%s",
-			t.clientID,
-			scope,
-			code,
-			t.redirectURI,
-			grant,
-		)),
-	)
+	// Listen on redirect URI
+	redirect, err := url.Parse(t.RedirectURI)
+	if err != nil {
+		return err
+	}
+	port := redirect.Port()
+	if port == "" {
+		port = "0"
+	}
+	listener, err := net.Listen("tcp", net.JoinHostPort(redirect.Hostname(), port))
 	if err != nil {
 		return err
 	}
+	if port == "0" {
+		_, port, _ = net.SplitHostPort(listener.Addr().String())
+		redirect.Host = net.JoinHostPort(redirect.Hostname(), port)
+	}
+	if redirect.Path == "" {
+		redirect.Path = "/"
+	}
+	redirectURI := redirect.String()
 
-	//Handle access token
-	body, _ := io.ReadAll(resp.Body)
-	return json.Unmarshal(body, t)
+	// Receive authorization code
+	type result struct {
+		code string
+		err  error
+	}
+	results := make(chan result, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc(redirect.Path, func(w http.ResponseWriter, req *http.Request) {
+		code, err := readAuthCode(req.URL.Query(), state)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			failurePage.Execute(w, err.Error())
+		} else {
+			io.WriteString(w, params.SuccessPage)
+		}
+
+		// Only the first response counts
+		select {
+		case results <- result{code, err}:
+		default:
+		}
+	})
+	server := &http.Server{Handler: mux}
+	go server.Serve(listener)
+	defer server.Close()
+
+	// Open sign-in page
+	err = params.OpenURL(t.authorityURL() + "/authorize?" + url.Values{
+		"client_id":             {t.ClientID},
+		"response_type":         {"code"},
+		"redirect_uri":          {redirectURI},
+		"response_mode":         {"query"},
+		"scope":                 {t.scope()},
+		"state":                 {state},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}.Encode())
+	if err != nil {
+		return err
+	}
+
+	// Wait for user
+	var res result
+	select {
+	case res = <-results:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+	server.Shutdown(shutdownCtx)
+	cancel()
+	if res.err != nil {
+		return res.err
+	}
+
+	//Request access token
+	return t.requestToken(ctx, url.Values{
+		"client_id":     {t.ClientID},
+		"scope":         {t.scope()},
+		"code":          {res.code},
+		"redirect_uri":  {redirectURI},
+		"grant_type":    {"authorization_code"},
+		"code_verifier": {verifier},
+	})
 }
 
-func (t *GraphToken) refresh() error {
-	grant := "refresh_token"
-	response, err := http.Post(
-		"https://login.microsoftonline.com/consumers/oauth2/v2.0/token",
-		"application/x-www-form-urlencoded",
-		bytes.NewBufferString(fmt.Sprintf(
-			"client_id=%s&scope=%s&refresh_token=%s&grant_type=%s",
-			t.clientID,
-			scope,
-			t.RefreshToken,
-			grant,
-		)),
-	)
+// Validates the query of the redirect, and returns the authorization code.
+func readAuthCode(qs url.Values, state string) (string, error) {
+	//Did the user decline?
+	if qs.Has("error") {
+		return "", &AuthError{
+			Code:        qs.Get("error"),
+			Description: qs.Get("error_description"),
+		}
+	}
+
+	//Verify state
+	rstate, ok := qs["state"]
+	if !ok {
+		return "", errors.New("query does not contain 'state'")
+	} else if len(rstate) != 1 {
+		return "", errors.New("'state' query has more/less than 1 value")
+	} else if rstate[0] != state {
+		return "", errors.New("invalid 'state' value in query")
+	}
+
+	//Get access code
+	rcode, ok := qs["code"]
+	if !ok {
+		return "", errors.New("query does not contain 'code'")
+	} else if len(rcode) != 1 {
+		return "", errors.New("'code' query has more/less than 1 value")
+	}
+	return rcode[0], nil
+}
+
+// Generates a random URL-safe string, for use as state and PKCE verifier.
+func randomString() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func (t *GraphToken) refresh(ctx context.Context) error {
+	// App-only tokens have no refresh token, get a new one instead
+	if t.credential != nil {
+		return t.generateNewApp(ctx)
+	}
+
+	return t.requestToken(ctx, url.Values{
+		"client_id":     {t.ClientID},
+		"scope":         {t.scope()},
+		"refresh_token": {t.RefreshToken},
+		"grant_type":    {"refresh_token"},
+	})
+}
+
+// Posts a form to the token endpoint.
+// On success, the token is updated with the response.
+// Error responses from the endpoint are returned as an *AuthError.
+func (t *GraphToken) requestToken(ctx context.Context, form url.Values) error {
+	issuedAt := time.Now()
+	response, err := t.postForm(ctx, "/token", form)
 	if err != nil {
 		return err
 	}
 
-	// Unmarshal new access token
+	// Read response body
 	defer response.Body.Close()
-	responseBody, _ := io.ReadAll(response.Body)
-	return json.Unmarshal(responseBody, t)
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+
+	// Error response?
+	if response.StatusCode != http.StatusOK {
+		return newAuthError(response, responseBody)
+	}
+
+	// Unmarshal new access token
+	if err := json.Unmarshal(responseBody, t); err != nil {
+		return err
+	}
+	t.ExpiresAt = issuedAt.Add(time.Duration(t.ExpiresIn) * time.Second)
+	return nil
+}
+
+// Posts a form to one of the OAuth endpoints.
+func (t *GraphToken) postForm(ctx context.Context, endpoint string, form url.Values) (*http.Response, error) {
+	request, err := http.NewRequestWithContext(
+		ctx,
+		"POST",
+		t.authorityURL()+endpoint,
+		strings.NewReader(form.Encode()),
+	)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if t.userAgent != "" {
+		request.Header.Set("User-Agent", t.userAgent)
+	}
+	return t.getHTTPClient().Do(request)
 }
@@ -0,0 +1,145 @@
+package gonedrive
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"time"
+)
+
+// Returned when app-only authentication is attempted without a specific tenant.
+var ErrTenantRequired = errors.New("app-only authentication requires a tenant ID")
+
+// Returned when the key for a certificate credential is not an RSA key.
+var ErrUnsupportedKey = errors.New("client certificate key must be an RSA key")
+
+// How long a signed client assertion is valid for.
+const clientAssertionLifetime = 10 * time.Minute
+
+// Authenticates the app itself when requesting app-only tokens.
+type clientCredential interface {
+	// Adds client authentication to a token request.
+	authenticate(t *GraphToken, form url.Values) error
+}
+
+// App-only access using the client credentials grant, for daemons and services.
+// The client secret is kept in memory only, and is never saved along with the token.
+// Since there is no refresh token, a new token is requested whenever the old one expires.
+//
+// config.Tenant must be the ID or domain of the tenant.
+// App-only tokens cannot use "/me/drive", so config.Drive should be set to
+// something like "/drives/{id}" or "/users/{id}/drive".
+func CreateAccessClientSecret(clientID string, clientSecret string, config TokenConfig) (*GraphToken, error) {
+	return CreateAccessClientSecretContext(context.Background(), clientID, clientSecret, config)
+}
+
+// Same as CreateAccessClientSecret, but the token request is bound to ctx.
+func CreateAccessClientSecretContext(ctx context.Context, clientID string, clientSecret string, config TokenConfig) (*GraphToken, error) {
+	return createAccessApp(ctx, clientID, clientSecretCredential(clientSecret), config)
+}
+
+// Same as CreateAccessClientSecret, but authenticates with a certificate instead.
+// The certificate must be registered with the app, and key must be its RSA private key.
+func CreateAccessClientCertificate(clientID string, cert *x509.Certificate, key crypto.Signer, config TokenConfig) (*GraphToken, error) {
+	return CreateAccessClientCertificateContext(context.Background(), clientID, cert, key, config)
+}
+
+// Same as CreateAccessClientCertificate, but the token request is bound to ctx.
+func CreateAccessClientCertificateContext(ctx context.Context, clientID string, cert *x509.Certificate, key crypto.Signer, config TokenConfig) (*GraphToken, error) {
+	if _, ok := key.Public().(*rsa.PublicKey); !ok {
+		return nil, ErrUnsupportedKey
+	}
+	thumbprint := sha1.Sum(cert.Raw)
+	return createAccessApp(ctx, clientID, &clientCertificateCredential{
+		thumbprint: base64.RawURLEncoding.EncodeToString(thumbprint[:]),
+		key:        key,
+	}, config)
+}
+
+func createAccessApp(ctx context.Context, clientID string, credential clientCredential, config TokenConfig) (*GraphToken, error) {
+	switch config.Tenant {
+	case "", TenantCommon, TenantConsumers, TenantOrganizations:
+		return nil, ErrTenantRequired
+	}
+
+	t := NewGraphToken(config)
+	t.ClientID = clientID
+	t.credential = credential
+	if err := t.refresh(ctx); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Requests a new app-only token.
+func (t *GraphToken) generateNewApp(ctx context.Context) error {
+	form := url.Values{
+		"client_id":  {t.ClientID},
+		"scope":      {t.appScope()},
+		"grant_type": {"client_credentials"},
+	}
+	if err := t.credential.authenticate(t, form); err != nil {
+		return err
+	}
+	return t.requestToken(ctx, form)
+}
+
+type clientSecretCredential string
+
+func (secret clientSecretCredential) authenticate(t *GraphToken, form url.Values) error {
+	form.Set("client_secret", string(secret))
+	return nil
+}
+
+type clientCertificateCredential struct {
+	thumbprint string
+	key        crypto.Signer
+}
+
+// Signs a JWT client assertion, as described here:
+// https://learn.microsoft.com/en-us/entra/identity-platform/certificate-credentials
+func (cert *clientCertificateCredential) authenticate(t *GraphToken, form url.Values) error {
+	jti, err := randomString()
+	if err != nil {
+		return err
+	}
+
+	// Build header and claims
+	now := time.Now()
+	header, _ := json.Marshal(map[string]string{
+		"alg": "RS256",
+		"typ": "JWT",
+		"x5t": cert.thumbprint,
+	})
+	claims, _ := json.Marshal(map[string]any{
+		"aud": t.authorityURL() + "/token",
+		"iss": t.ClientID,
+		"sub": t.ClientID,
+		"jti": jti,
+		"nbf": now.Unix(),
+		"iat": now.Unix(),
+		"exp": now.Add(clientAssertionLifetime).Unix(),
+	})
+
+	// Sign it
+	enc := base64.RawURLEncoding
+	assertion := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(assertion))
+	signature, err := cert.key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		return err
+	}
+	assertion += "." + enc.EncodeToString(signature)
+
+	form.Set("client_assertion_type", "urn:ietf:params:oauth:client-assertion-type:jwt-bearer")
+	form.Set("client_assertion", assertion)
+	return nil
+}
@@ -0,0 +1,110 @@
+package gonedrive
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Returned by the device code flow when the user does not finish signing in
+// before the device code expires.
+var ErrDeviceCodeExpired = errors.New("device code expired before sign-in completed")
+
+// Device authorization response.
+// The user should visit VerificationURI, and enter UserCode there.
+type DeviceCode struct {
+	UserCode        string `json:"user_code"`
+	DeviceCode      string `json:"device_code"`
+	VerificationURI string `json:"verification_uri"`
+	ExpiresIn       int    `json:"expires_in"`
+	Interval        int    `json:"interval"`
+	Message         string `json:"message"`
+}
+
+// Called once the device code has been issued.
+// It should present the user code and verification URL to the user.
+type DeviceCodeFn func(code *DeviceCode)
+
+// Alternative to CreateAccess, for machines without a browser.
+// Instead of opening a browser window, the user is asked to enter a code on
+// another device. promptFn is called with the code to show to the user,
+// if it is nil, the message is printed to stdout instead.
+// This call blocks until the user has signed in, or the code expires.
+func CreateAccessDeviceCode(clientID string, refresh *GraphToken, promptFn DeviceCodeFn) (*GraphToken, error) {
+	return CreateAccessDeviceCodeContext(context.Background(), clientID, refresh, promptFn)
+}
+
+// Same as CreateAccessDeviceCode, but gives up waiting for the user once ctx is done.
+func CreateAccessDeviceCodeContext(ctx context.Context, clientID string, refresh *GraphToken, promptFn DeviceCodeFn) (*GraphToken, error) {
+	return createAccess(ctx, clientID, "", refresh, func(t *GraphToken) error {
+		return t.generateNewDeviceCode(ctx, promptFn)
+	})
+}
+
+func (t *GraphToken) generateNewDeviceCode(ctx context.Context, promptFn DeviceCodeFn) error {
+	// Request device code
+	response, err := t.postForm(ctx, "/devicecode", url.Values{
+		"client_id": {t.ClientID},
+		"scope":     {t.scope()},
+	})
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode != http.StatusOK {
+		return newAuthError(response, responseBody)
+	}
+	code := new(DeviceCode)
+	if err := json.Unmarshal(responseBody, code); err != nil {
+		return err
+	}
+
+	// Show code to user
+	if promptFn != nil {
+		promptFn(code)
+	} else {
+		fmt.Println(code.Message)
+	}
+
+	// Poll until the user is done
+	interval := time.Duration(max(code.Interval, 1)) * time.Second
+	deadline := time.Now().Add(time.Duration(code.ExpiresIn) * time.Second)
+	for time.Now().Before(deadline) {
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		err := t.requestToken(ctx, url.Values{
+			"client_id":   {t.ClientID},
+			"device_code": {code.DeviceCode},
+			"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+		})
+
+		// Keep waiting?
+		var authErr *AuthError
+		if errors.As(err, &authErr) {
+			switch authErr.Code {
+			case "authorization_pending":
+				continue
+			case "slow_down":
+				interval += 5 * time.Second
+				continue
+			case "expired_token":
+				return ErrDeviceCodeExpired
+			}
+		}
+		return err
+	}
+
+	return ErrDeviceCodeExpired
+}
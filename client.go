@@ -0,0 +1,147 @@
+package gonedrive
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+// Supplies access tokens for a Client.
+// *GraphToken implements this, and refreshes itself as needed.
+type TokenSource interface {
+	// Returns a valid access token.
+	Token(ctx context.Context) (string, error)
+
+	// Called when the API rejects a token returned by Token.
+	// The next call to Token should return a new token.
+	Invalidate(token string)
+}
+
+// Client for the MS graph API.
+// All drive operations are available as methods on the client.
+// A Client is safe for concurrent use.
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+	userAgent  string
+	tokens     TokenSource
+	drive      string
+	logger     *slog.Logger
+	retry      *RetryPolicy
+	syncVerify bool
+}
+
+type ClientOption func(c *Client)
+
+// Sets the HTTP client used for API requests.
+// Defaults to http.DefaultClient.
+//
+// If the token source is a *GraphToken without an HTTP client of its own,
+// it refreshes through this client too.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// Sets the base URL for API requests, including the API version.
+// Defaults to the Graph URL of the token source if it is a *GraphToken,
+// otherwise "https://graph.microsoft.com/v1.0".
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) {
+		c.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// Sets the User-Agent header sent with API requests.
+// Token requests from a *GraphToken token source send it as well.
+func WithUserAgent(userAgent string) ClientOption {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// Sets where access tokens come from.
+// Without a token source, requests are sent without authorization.
+func WithTokenSource(tokens TokenSource) ClientOption {
+	return func(c *Client) {
+		c.tokens = tokens
+	}
+}
+
+// Sets the drive used by item operations, such as DriveByID or SiteDrive.
+// Defaults to the drive of the token source if it is a *GraphToken,
+// otherwise MyDrive.
+func WithDrive(drive DriveRef) ClientOption {
+	return func(c *Client) {
+		c.drive = drive.path()
+	}
+}
+
+// Sets the logger used for request and token diagnostics.
+// Nothing is logged by default.
+func WithLogger(logger *slog.Logger) ClientOption {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// Makes SyncFolder check downloaded files against the size and QuickXorHash
+// reported by the API, see NewVerifyingReader.
+// Files that do not match are removed again, and reported as errors.
+func WithSyncVerify(verify bool) ClientOption {
+	return func(c *Client) {
+		c.syncVerify = verify
+	}
+}
+
+// Creates a new client.
+// Usually, at least a token source should be given.
+func NewClient(opts ...ClientOption) *Client {
+	c := &Client{}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	// Fill in defaults
+	config := &TokenConfig{}
+	if t, ok := c.tokens.(*GraphToken); ok {
+		config = &t.TokenConfig
+		t.setClientDefaults(c.httpClient, c.userAgent)
+	}
+	if c.httpClient == nil {
+		c.httpClient = http.DefaultClient
+	}
+	if c.baseURL == "" {
+		c.baseURL = config.graphEndpoint()
+	}
+	if c.drive == "" {
+		c.drive = config.drivePath()
+	}
+	if c.retry == nil {
+		policy := DefaultRetryPolicy
+		c.retry = &policy
+	}
+	return c
+}
+
+// Client used by the methods on GraphToken.
+func (t *GraphToken) client(opts ...ClientOption) *Client {
+	return NewClient(append([]ClientOption{
+		WithTokenSource(t),
+		WithHTTPClient(t.httpClient),
+	}, opts...)...)
+}
+
+func (c *Client) debug(msg string, args ...any) {
+	if c.logger != nil {
+		c.logger.Debug(msg, args...)
+	}
+}
+
+func (c *Client) warn(msg string, args ...any) {
+	if c.logger != nil {
+		c.logger.Warn(msg, args...)
+	}
+}
@@ -0,0 +1,116 @@
+package gonedrive
+
+import "strings"
+
+// Tenants for use in TokenConfig.
+// Work/school accounts can also use the tenant ID or domain name of their organization.
+const (
+	TenantCommon        = "common"
+	TenantOrganizations = "organizations"
+	TenantConsumers     = "consumers"
+)
+
+// Authority hosts for the Microsoft identity platform.
+const (
+	AuthorityGlobal = "https://login.microsoftonline.com"
+	AuthorityUSGov  = "https://login.microsoftonline.us"
+	AuthorityChina  = "https://login.chinacloudapi.cn"
+)
+
+// Graph API hosts for the global service and national clouds.
+const (
+	GraphGlobal    = "https://graph.microsoft.com"
+	GraphUSGovL4   = "https://graph.microsoft.us"
+	GraphUSGovL5   = "https://dod-graph.microsoft.us"
+	GraphChina     = "https://microsoftgraph.chinacloudapi.cn"
+	APIVersionV1   = "v1.0"
+	APIVersionBeta = "beta"
+)
+
+// Default scopes requested when signing in.
+var DefaultScopes = []string{"Files.ReadWrite", "offline_access"}
+
+// Controls where a token is obtained from, and which API it is used against.
+// Zero values fall back to personal Microsoft accounts on the global Graph service.
+//
+// The config is saved along with the token. To use a non-default config,
+// set it on the token passed to CreateAccess.
+type TokenConfig struct {
+	// "consumers", "organizations", "common" or a tenant ID.
+	Tenant string `json:"tenant,omitempty"`
+
+	// Host of the identity platform, such as AuthorityGlobal.
+	AuthorityHost string `json:"authority_host,omitempty"`
+
+	// Host of the Graph API, such as GraphGlobal.
+	GraphURL string `json:"graph_url,omitempty"`
+
+	// Graph API version, such as APIVersionV1 or APIVersionBeta.
+	APIVersion string `json:"api_version,omitempty"`
+
+	// Scopes requested when signing in.
+	Scopes []string `json:"scopes,omitempty"`
+
+	// Drive used by item operations, such as DriveByID or SiteDrive.
+	// Defaults to MyDrive, the drive of the signed-in user.
+	Drive DriveRef `json:"drive,omitempty"`
+}
+
+// Creates an empty token using the given config.
+// Pass it to CreateAccess to sign in.
+func NewGraphToken(config TokenConfig) *GraphToken {
+	return &GraphToken{TokenConfig: config}
+}
+
+// Base URL of the OAuth endpoints.
+func (c *TokenConfig) authorityURL() string {
+	host := c.AuthorityHost
+	if host == "" {
+		host = AuthorityGlobal
+	}
+	tenant := c.Tenant
+	if tenant == "" {
+		tenant = TenantConsumers
+	}
+	return strings.TrimSuffix(host, "/") + "/" + tenant + "/oauth2/v2.0"
+}
+
+// Base URL for Graph API requests, including the API version.
+func (c *TokenConfig) graphEndpoint() string {
+	host := c.GraphURL
+	if host == "" {
+		host = GraphGlobal
+	}
+	version := c.APIVersion
+	if version == "" {
+		version = APIVersionV1
+	}
+	return strings.TrimSuffix(host, "/") + "/" + version
+}
+
+// Path of the drive used by item operations.
+func (c *TokenConfig) drivePath() string {
+	return c.Drive.path()
+}
+
+// Space separated list of scopes to request for app-only tokens.
+// These can only use the ".default" scope of the Graph API.
+func (c *TokenConfig) appScope() string {
+	if len(c.Scopes) != 0 {
+		return c.scope()
+	}
+	host := c.GraphURL
+	if host == "" {
+		host = GraphGlobal
+	}
+	return strings.TrimSuffix(host, "/") + "/.default"
+}
+
+// Space separated list of scopes to request.
+func (c *TokenConfig) scope() string {
+	scopes := c.Scopes
+	if len(scopes) == 0 {
+		scopes = DefaultScopes
+	}
+	return strings.Join(scopes, " ")
+}
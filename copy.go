@@ -0,0 +1,176 @@
+package gonedrive
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+var ErrCopyFailed = errors.New("copy failed")
+
+// Default time between polls of a copy monitor.
+const DefaultCopyPollInterval = time.Second
+
+// State of a copy, as reported by its monitor.
+type CopyStatus struct {
+	// One of "notStarted", "inProgress", "completed", "updating",
+	// "failed", "deletePending", "deleteFailed" or "waiting".
+	Status string `json:"status"`
+
+	// How far the copy is, from 0 to 100.
+	PercentageComplete float64 `json:"percentageComplete"`
+
+	// ID of the new item, once the copy has completed.
+	ResourceId string `json:"resourceId"`
+
+	// Why the copy failed, if it did.
+	Error *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Reports whether the copy has finished, successfully or not.
+func (status *CopyStatus) Done() bool {
+	return status.Status == "completed" || status.Status == "failed"
+}
+
+// A copy running on the server, as started by CopyItem.
+type CopyOperation struct {
+	// Time between polls in Wait.
+	// Defaults to DefaultCopyPollInterval.
+	PollInterval time.Duration
+
+	// Called with every status polled in Wait, if set.
+	OnProgress func(status *CopyStatus)
+
+	client     *Client
+	monitorURL string
+	destDrive  DriveRef
+}
+
+// Copies an item into destParent, which may be in another drive.
+// If newName is empty, the copy keeps the name of the original.
+// Folders are copied along with everything in them.
+//
+// The copy is done by the server, and may take a while for large items.
+// Use the returned handle to follow it.
+func (c *Client) CopyItem(ctx context.Context, src ItemRef, destParent ItemRef, newName string) (*CopyOperation, error) {
+	if newName != "" {
+		if err := ValidateName(newName); err != nil {
+			return nil, err
+		}
+	}
+	endpoint, err := c.itemEndpoint(src, "copy")
+	if err != nil {
+		return nil, err
+	}
+
+	// Find destination, by ID
+	parent, err := c.GetDriveItem(ctx, destParent, NewQuery().Select("id", "parentReference"))
+	if err != nil {
+		return nil, err
+	}
+	driveID := ""
+	if parent.ParentReference != nil {
+		driveID = parent.ParentReference.DriveId
+	}
+	if driveID == "" {
+		drive, err := c.OnDrive(destParent.Drive).GetDrive(ctx)
+		if err != nil {
+			return nil, err
+		}
+		driveID = drive.Id
+	}
+
+	// Create request body
+	body := map[string]any{
+		"parentReference": map[string]any{
+			"driveId": driveID,
+			"id":      parent.Id,
+		},
+	}
+	if newName != "" {
+		body["name"] = newName
+	}
+	requestData, _ := json.Marshal(body)
+
+	// Start copy
+	response, err := c.MakeRequestContext(ctx, "POST", endpoint, bytes.NewReader(requestData), "application/json")
+	if err != nil {
+		return nil, err
+	}
+	response.Body.Close()
+	monitorURL := response.Header.Get("Location")
+	if monitorURL == "" {
+		return nil, fmt.Errorf("%w: no monitor URL in response", ErrCopyFailed)
+	}
+	return &CopyOperation{
+		client:     c,
+		monitorURL: monitorURL,
+		destDrive:  DriveByID(driveID),
+	}, nil
+}
+
+// URL of the monitor, which can be polled without authorization.
+func (op *CopyOperation) MonitorURL() string {
+	return op.monitorURL
+}
+
+// Polls the monitor once.
+func (op *CopyOperation) Status(ctx context.Context) (*CopyStatus, error) {
+	// The monitor rejects requests with an access token
+	request, err := http.NewRequestWithContext(ctx, "GET", op.monitorURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if op.client.userAgent != "" {
+		request.Header.Set("User-Agent", op.client.userAgent)
+	}
+	return SendRequest[CopyStatus](op.client, request)
+}
+
+// Polls the monitor until the copy is done, and returns the new item.
+// If the copy fails, the error matches ErrCopyFailed.
+// Cancelling ctx stops the polling, but not the copy itself.
+func (op *CopyOperation) Wait(ctx context.Context) (*DriveItem, error) {
+	interval := op.PollInterval
+	if interval <= 0 {
+		interval = DefaultCopyPollInterval
+	}
+
+	for {
+		status, err := op.Status(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if op.OnProgress != nil {
+			op.OnProgress(status)
+		}
+
+		// Done yet?
+		switch status.Status {
+		case "completed":
+			if status.ResourceId == "" {
+				return nil, fmt.Errorf("%w: monitor did not report the new item", ErrCopyFailed)
+			}
+			return op.client.GetDriveItem(ctx, ItemByID(status.ResourceId).OnDrive(op.destDrive), nil)
+		case "failed":
+			if status.Error != nil {
+				return nil, fmt.Errorf("%w: %s: %s", ErrCopyFailed, status.Error.Code, status.Error.Message)
+			}
+			return nil, ErrCopyFailed
+		}
+
+		// Wait a bit
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package gonedrive
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+)
+
+// Moves an item to the recycle bin.
+// If etag is not empty, the item is only deleted if it has not changed since,
+// otherwise the error matches ErrPreconditionFailed.
+func (c *Client) DeleteItem(ctx context.Context, item ItemRef, etag string) error {
+	return c.deleteItem(ctx, "DELETE", item, "", etag)
+}
+
+// Deletes an item without moving it to the recycle bin, so it cannot be restored.
+// This is only supported by OneDrive for Business and SharePoint drives.
+// etag works the same way as for DeleteItem.
+func (c *Client) PermanentDelete(ctx context.Context, item ItemRef, etag string) error {
+	return c.deleteItem(ctx, "POST", item, "permanentDelete", etag)
+}
+
+func (c *Client) deleteItem(ctx context.Context, method string, item ItemRef, action string, etag string) error {
+	endpoint, err := c.itemEndpoint(item, action)
+	if err != nil {
+		return err
+	}
+	request, err := c.BuildRequestContext(ctx, method, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	if etag != "" {
+		request.Header.Set("If-Match", etag)
+	}
+	response, err := c.SendRequest(request)
+	if err != nil {
+		return err
+	}
+	return response.Body.Close()
+}
+
+// Restores a deleted item from the recycle bin.
+// Deleted items can only be found by ID.
+// If parentID is empty, the item is put back where it was deleted from.
+// If name is empty, it keeps its original name.
+// This is only supported by OneDrive Personal.
+func (c *Client) Restore(ctx context.Context, item ItemRef, parentID string, name string) (*DriveItem, error) {
+	if name != "" {
+		if err := ValidateName(name); err != nil {
+			return nil, err
+		}
+	}
+	endpoint, err := c.itemEndpoint(item, "restore")
+	if err != nil {
+		return nil, err
+	}
+
+	// Create request body
+	body := map[string]any{}
+	if parentID != "" {
+		body["parentReference"] = map[string]any{"id": parentID}
+	}
+	if name != "" {
+		body["name"] = name
+	}
+	requestData, _ := json.Marshal(body)
+
+	// Restore item
+	request, err := c.BuildRequestContext(ctx, "POST", endpoint, bytes.NewReader(requestData))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	return SendRequest[DriveItem](c, request)
+}
@@ -0,0 +1,308 @@
+package gonedrive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+)
+
+var ErrNotFile = errors.New("item is not a file")
+
+// Default number of bytes fetched at once by RemoteFile reads.
+const DefaultReadAhead = 1 << 20
+
+// A remote file, read with range requests against its download URL.
+// Implements io.ReaderAt and io.ReadSeeker, so only the parts of the file
+// that are actually read are downloaded.
+//
+// ReadAt is safe for concurrent use, Read and Seek are not.
+type RemoteFile struct {
+	// Minimum number of bytes fetched per request.
+	// Small reads are served from what was fetched before, if possible.
+	// Defaults to DefaultReadAhead, 0 or less disables read-ahead.
+	ReadAhead int
+
+	ctx    context.Context
+	client *Client
+	ref    ItemRef
+	offset int64
+
+	// Guarded by mux
+	mux    sync.Mutex
+	item   *DriveItem
+	buf    []byte
+	bufOff int64
+}
+
+// Opens a remote file for reading.
+// All requests made by the returned file are bound to ctx.
+func (c *Client) OpenFile(ctx context.Context, ref ItemRef) (*RemoteFile, error) {
+	f := &RemoteFile{
+		ReadAhead: DefaultReadAhead,
+		ctx:       ctx,
+		client:    c,
+		ref:       ref,
+	}
+	if _, err := f.refresh(""); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// The file, as it was when it was opened.
+func (f *RemoteFile) Item() *DriveItem {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	return f.item
+}
+
+// Size of the file, in bytes.
+func (f *RemoteFile) Size() int64 {
+	return f.Item().Size
+}
+
+// Looks up the file again, for a fresh download URL.
+// Download URLs expire after a while.
+// Does nothing if the URL has changed since stale was handed out.
+func (f *RemoteFile) refresh(stale string) (string, error) {
+	f.mux.Lock()
+	defer f.mux.Unlock()
+	if f.item != nil && f.item.DownloadURL != stale {
+		return f.item.DownloadURL, nil
+	}
+
+	item, err := f.client.GetDriveItem(f.ctx, f.ref, nil)
+	if err != nil {
+		return "", err
+	}
+	if item.IsDir() {
+		return "", fmt.Errorf("%w: %s", ErrNotFile, f.ref)
+	}
+	if f.item != nil && item.Etag != f.item.Etag {
+		return "", fmt.Errorf("%w: file changed while reading", ErrPreconditionFailed)
+	}
+	f.item = item
+	return item.DownloadURL, nil
+}
+
+// Requests the file from off, up to but not including end.
+// If end is negative, the rest of the file is requested.
+func (f *RemoteFile) openRange(off int64, end int64) (io.ReadCloser, error) {
+	downloadURL := f.Item().DownloadURL
+	for refreshed := false; ; refreshed = true {
+		// Download URLs are pre-authenticated
+		request, err := http.NewRequestWithContext(f.ctx, "GET", downloadURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		if end < 0 {
+			request.Header.Set("Range", fmt.Sprintf("bytes=%d-", off))
+		} else {
+			request.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", off, end-1))
+		}
+		response, err := f.client.SendRequest(request)
+
+		// Expired URL?
+		var errResp *ErrorResponse
+		if !refreshed && errors.As(err, &errResp) && (errResp.StatusCode == http.StatusUnauthorized || errResp.StatusCode == http.StatusForbidden) {
+			if downloadURL, err = f.refresh(downloadURL); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// Range ignored?
+		if response.StatusCode != http.StatusPartialContent {
+			if _, err := io.CopyN(io.Discard, response.Body, off); err != nil {
+				response.Body.Close()
+				return nil, err
+			}
+		}
+		return response.Body, nil
+	}
+}
+
+// Reads p from off, resuming the request if the connection breaks.
+func (f *RemoteFile) fetch(p []byte, off int64) (int, error) {
+	n := 0
+	attempts := max(f.client.retry.MaxAttempts, 1)
+	for attempt := 1; n < len(p); attempt++ {
+		body, err := f.openRange(off+int64(n), off+int64(len(p)))
+		if err != nil {
+			return n, err
+		}
+		read, err := io.ReadFull(body, p[n:])
+		body.Close()
+		n += read
+		if err != nil && (attempt >= attempts || f.ctx.Err() != nil) {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// Reads len(p) bytes from off, as described by io.ReaderAt.
+func (f *RemoteFile) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, fmt.Errorf("gonedrive: negative offset %d", off)
+	}
+	size := f.Size()
+	if off >= size {
+		return 0, io.EOF
+	}
+
+	// Stop at end of file
+	var eof error
+	if off+int64(len(p)) > size {
+		p = p[:size-off]
+		eof = io.EOF
+	}
+
+	// Already fetched?
+	f.mux.Lock()
+	if off >= f.bufOff && off+int64(len(p)) <= f.bufOff+int64(len(f.buf)) {
+		n := copy(p, f.buf[off-f.bufOff:])
+		f.mux.Unlock()
+		return n, eof
+	}
+	f.mux.Unlock()
+
+	// Large reads go straight into p
+	if len(p) >= f.ReadAhead {
+		n, err := f.fetch(p, off)
+		if err == nil {
+			err = eof
+		}
+		return n, err
+	}
+
+	// Fetch more than asked for, and keep it for later
+	buf := make([]byte, min(int64(f.ReadAhead), size-off))
+	n, err := f.fetch(buf, off)
+	if err != nil {
+		return copy(p, buf[:n]), err
+	}
+	f.mux.Lock()
+	f.buf = buf
+	f.bufOff = off
+	f.mux.Unlock()
+	return copy(p, buf), eof
+}
+
+// Reads from the current offset, as described by io.Reader.
+func (f *RemoteFile) Read(p []byte) (int, error) {
+	n, err := f.ReadAt(p, f.offset)
+	f.offset += int64(n)
+	if err == io.EOF && n != 0 {
+		err = nil
+	}
+	return n, err
+}
+
+// Sets the offset for the next Read, as described by io.Seeker.
+func (f *RemoteFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += f.Size()
+	default:
+		return 0, fmt.Errorf("gonedrive: invalid whence %d", whence)
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("gonedrive: negative offset %d", offset)
+	}
+	f.offset = offset
+	return offset, nil
+}
+
+// Downloads a file to localPath.
+// If localPath already holds part of the file, for example from an earlier
+// download that was interrupted, the download continues where it left off.
+// Broken connections are resumed as well.
+//
+// Once done, the local file is checked against the size and hashes of the
+// remote file. If a resumed download does not match, because the remote file
+// changed since the partial download, it is downloaded again from scratch.
+// If that does not match either, a *HashMismatchError is returned.
+func (c *Client) DownloadToFile(ctx context.Context, ref ItemRef, localPath string) (*DriveItem, error) {
+	remote, err := c.OpenFile(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	size := remote.Size()
+
+	// How much do we have?
+	local, err := os.OpenFile(localPath, os.O_CREATE|os.O_RDWR, 0666)
+	if err != nil {
+		return nil, err
+	}
+	defer local.Close()
+	pos, err := local.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	resumed := pos > 0 && pos <= size
+	for {
+		// Start over?
+		if !resumed {
+			if err := local.Truncate(0); err != nil {
+				return nil, err
+			}
+			if pos, err = local.Seek(0, io.SeekStart); err != nil {
+				return nil, err
+			}
+		}
+
+		// Download the rest
+		if err := c.downloadTail(ctx, remote, local, pos); err != nil {
+			return nil, err
+		}
+
+		// Check the whole file, including what was there before
+		verifier := newHashVerifier(remote.Item())
+		if _, err := io.Copy(verifier, io.NewSectionReader(local, 0, size+1)); err != nil {
+			return nil, err
+		}
+		err = verifier.verify()
+		if err == nil {
+			return remote.Item(), local.Close()
+		}
+		if !resumed {
+			return nil, err
+		}
+		c.warn("resumed download does not match, starting over", "path", localPath, "err", err)
+		resumed = false
+	}
+}
+
+// Downloads the remote file from pos to the end, appending it to local.
+func (c *Client) downloadTail(ctx context.Context, remote *RemoteFile, local *os.File, pos int64) error {
+	size := remote.Size()
+	if _, err := local.Seek(pos, io.SeekStart); err != nil {
+		return err
+	}
+	attempts := max(c.retry.MaxAttempts, 1)
+	for attempt := 1; pos < size; attempt++ {
+		body, err := remote.openRange(pos, -1)
+		if err != nil {
+			return err
+		}
+		n, err := io.Copy(local, io.LimitReader(body, size-pos))
+		body.Close()
+		pos += n
+		if err != nil && (attempt >= attempts || ctx.Err() != nil) {
+			return err
+		}
+	}
+	return nil
+}
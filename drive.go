@@ -0,0 +1,105 @@
+package gonedrive
+
+import (
+	"context"
+	"net/url"
+	"strings"
+)
+
+// Reference to a drive, which is the path of the drive in the API.
+// The zero value refers to the drive of the signed-in user.
+type DriveRef string
+
+// Drive of the signed-in user.
+const MyDrive = DriveRef("/me/drive")
+
+// Drive with the given ID.
+func DriveByID(id string) DriveRef {
+	return DriveRef("/drives/" + escapeID(id))
+}
+
+// OneDrive of a user, by ID or user principal name.
+func UserDrive(user string) DriveRef {
+	return DriveRef("/users/" + escapeID(user) + "/drive")
+}
+
+// Document library of a Microsoft 365 group.
+func GroupDrive(group string) DriveRef {
+	return DriveRef("/groups/" + escapeID(group) + "/drive")
+}
+
+// Default document library of a SharePoint site.
+// Use ListSiteDrives to find the other libraries of a site.
+func SiteDrive(site string) DriveRef {
+	return DriveRef("/sites/" + escapeID(site) + "/drive")
+}
+
+// Escapes an ID for use in a URL path.
+// Commas are kept, as SharePoint site IDs are made of comma separated parts.
+func escapeID(id string) string {
+	return strings.ReplaceAll(url.PathEscape(id), "%2C", ",")
+}
+
+// Path of the drive, without trailing slash.
+func (d DriveRef) path() string {
+	if d == "" {
+		return string(MyDrive)
+	}
+	return "/" + strings.Trim(string(d), "/")
+}
+
+// A drive, such as a OneDrive or a document library.
+type Drive struct {
+	Id          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	DriveType   string `json:"driveType"`
+	WebURL      string `json:"webUrl"`
+
+	Quota *struct {
+		Total     int64  `json:"total"`
+		Used      int64  `json:"used"`
+		Remaining int64  `json:"remaining"`
+		Deleted   int64  `json:"deleted"`
+		State     string `json:"state"`
+	} `json:"quota"`
+}
+
+// Reference to the drive, by ID.
+func (d *Drive) Ref() DriveRef {
+	return DriveByID(d.Id)
+}
+
+// Returns a copy of the client, which uses the given drive for item operations.
+// The copy shares everything else with the original.
+func (c *Client) OnDrive(drive DriveRef) *Client {
+	copied := *c
+	copied.drive = drive.path()
+	return &copied
+}
+
+// Get information about the drive used by item operations.
+func (c *Client) GetDrive(ctx context.Context) (*Drive, error) {
+	return MakeRequestContext[Drive](ctx, c, "GET", c.drive, nil)
+}
+
+// Lists the drives available to the signed-in user.
+func (c *Client) ListDrives(ctx context.Context) ([]*Drive, error) {
+	return c.listDrives(ctx, "/me/drives")
+}
+
+// Lists the document libraries of a SharePoint site.
+func (c *Client) ListSiteDrives(ctx context.Context, site string) ([]*Drive, error) {
+	return c.listDrives(ctx, "/sites/"+escapeID(site)+"/drives")
+}
+
+func (c *Client) listDrives(ctx context.Context, endpoint string) ([]*Drive, error) {
+	drives := []*Drive{}
+	for drive, err := range Paginate[*Drive](ctx, c, endpoint) {
+		if err != nil {
+			return nil, err
+		}
+		drives = append(drives, drive)
+	}
+	return drives, nil
+}
@@ -1,8 +1,113 @@
 package gonedrive
 
-import "strings"
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode"
+)
 
-// Builds the "$select" query string from a list of wanted results
+// Matches any *InvalidPathError, using errors.Is.
+var ErrInvalidPath = errors.New("invalid path")
+
+// Characters OneDrive does not allow in names.
+const forbiddenChars = "\"*:<>?/\\|"
+
+// Names OneDrive does not allow, compared case insensitively.
+var reservedNames = []string{
+	".lock", "CON", "PRN", "AUX", "NUL", "desktop.ini",
+	"COM0", "COM1", "COM2", "COM3", "COM4", "COM5", "COM6", "COM7", "COM8", "COM9",
+	"LPT0", "LPT1", "LPT2", "LPT3", "LPT4", "LPT5", "LPT6", "LPT7", "LPT8", "LPT9",
+}
+
+// Maximum length of a single name.
+const maxNameLength = 255
+
+// Returned when a path contains a name OneDrive does not allow.
+type InvalidPathError struct {
+	Path   string
+	Name   string
+	Reason string
+}
+
+func (err *InvalidPathError) Error() string {
+	return fmt.Sprintf("invalid path \"%s\": name \"%s\" %s", err.Path, err.Name, err.Reason)
+}
+
+func (err *InvalidPathError) Is(target error) bool {
+	return target == ErrInvalidPath
+}
+
+// Checks whether OneDrive allows name as a file or folder name.
+// Returns an *InvalidPathError if it does not.
+func ValidateName(name string) error {
+	reason := ""
+	switch {
+	case name == "":
+		reason = "is empty"
+	case len(name) > maxNameLength:
+		reason = fmt.Sprintf("is longer than %d bytes", maxNameLength)
+	case strings.ContainsAny(name, forbiddenChars):
+		reason = fmt.Sprintf("contains one of the characters %s", forbiddenChars)
+	case strings.ContainsFunc(name, unicode.IsControl):
+		reason = "contains control characters"
+	case strings.HasSuffix(name, ".") || strings.HasSuffix(name, " "):
+		reason = "ends with a dot or space"
+	case strings.HasPrefix(name, " "):
+		reason = "starts with a space"
+	case strings.HasPrefix(name, "~$"):
+		reason = "starts with ~$"
+	case strings.Contains(strings.ToLower(name), "_vti_"):
+		reason = "contains _vti_"
+	}
+	for _, reserved := range reservedNames {
+		if strings.EqualFold(name, reserved) {
+			reason = "is reserved"
+		}
+	}
+
+	if reason != "" {
+		return &InvalidPathError{Path: name, Name: name, Reason: reason}
+	}
+	return nil
+}
+
+// Checks every name in a slash separated path, using ValidateName.
+// Leading, trailing and repeated slashes are ignored.
+func ValidatePath(path string) error {
+	for _, name := range splitPath(path) {
+		if err := ValidateName(name); err != nil {
+			err.(*InvalidPathError).Path = path
+			return err
+		}
+	}
+	return nil
+}
+
+// Splits a path into its names, skipping empty ones.
+func splitPath(path string) []string {
+	names := []string{}
+	for _, name := range strings.Split(path, "/") {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Escapes each name in a path for use in a URL.
+// Colons are escaped as well, as they delimit paths in item addresses.
+func escapePath(path string) string {
+	names := splitPath(path)
+	for i, name := range names {
+		names[i] = strings.ReplaceAll(url.PathEscape(name), ":", "%3A")
+	}
+	return strings.Join(names, "/")
+}
+
+// Builds the "$select" query string from a list of wanted results.
+// See Query for building complete queries.
 func EndpointSelect(names ...string) string {
 	if len(names) == 0 {
 		return ""
@@ -11,7 +116,9 @@ func EndpointSelect(names ...string) string {
 }
 
 // Helper function, creates URL from path and query.
+// Each name in the path is escaped, and leading/trailing slashes are ignored.
 func EndpointPath(path string, endpoint string, query ...string) string {
+	path = escapePath(path)
 	o := "root:/" + path
 	if endpoint != "" {
 		o += ":/" + endpoint
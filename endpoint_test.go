@@ -0,0 +1,90 @@
+package gonedrive
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestEscapePath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"", ""},
+		{"/", ""},
+		{"a b/c#d%e", "a%20b/c%23d%25e"},
+		{"a:b", "a%3Ab"},
+		{"?x=1&y", "%3Fx=1&y"},
+		{"//a///b/", "a/b"},
+		{"æøå", "%C3%A6%C3%B8%C3%A5"},
+	}
+	for _, test := range tests {
+		if got := escapePath(test.path); got != test.want {
+			t.Errorf("escapePath(%q) = %q, want %q", test.path, got, test.want)
+		}
+	}
+}
+
+func TestEndpointPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		endpoint string
+		query    []string
+		want     string
+	}{
+		{"", "", nil, "root"},
+		{"/", "children", nil, "root/children"},
+		{"/a b/", "", nil, "root:/a%20b"},
+		{"a/b:c", "content", nil, "root:/a/b%3Ac:/content"},
+		{"a", "children", []string{"$top=1", "$select=id"}, "root:/a:/children?$top=1&$select=id"},
+	}
+	for _, test := range tests {
+		if got := EndpointPath(test.path, test.endpoint, test.query...); got != test.want {
+			t.Errorf("EndpointPath(%q, %q) = %q, want %q", test.path, test.endpoint, got, test.want)
+		}
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	valid := []string{"a", "file.txt", ".hidden", "a b", "CONSOLE", "com10", "~file", strings.Repeat("a", maxNameLength)}
+	for _, name := range valid {
+		if err := ValidateName(name); err != nil {
+			t.Errorf("ValidateName(%q): %v", name, err)
+		}
+	}
+
+	invalid := []string{
+		"", strings.Repeat("a", maxNameLength+1),
+		"a:b", "a/b", "a\\b", "a*", "a?", "\"a\"", "<a>", "a|b", "a\x00b", "a\tb",
+		"a.", "a ", " a",
+		"~$doc.docx", "a_vti_b", "A_VTI_B",
+		"con", "CON", "Lpt1", "desktop.ini", ".lock",
+	}
+	for _, name := range invalid {
+		err := ValidateName(name)
+		if !errors.Is(err, ErrInvalidPath) {
+			t.Errorf("ValidateName(%q) = %v, want ErrInvalidPath", name, err)
+			continue
+		}
+		var pathErr *InvalidPathError
+		if !errors.As(err, &pathErr) || pathErr.Name != name {
+			t.Errorf("ValidateName(%q) = %#v", name, err)
+		}
+	}
+}
+
+func TestValidatePath(t *testing.T) {
+	if err := ValidatePath("/a/b c//d.txt/"); err != nil {
+		t.Errorf("valid path: %v", err)
+	}
+
+	err := ValidatePath("a/con/b")
+	var pathErr *InvalidPathError
+	if !errors.As(err, &pathErr) {
+		t.Fatalf("got %v, want *InvalidPathError", err)
+	}
+	if pathErr.Path != "a/con/b" || pathErr.Name != "con" {
+		t.Errorf("got path %q and name %q", pathErr.Path, pathErr.Name)
+	}
+}
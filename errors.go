@@ -0,0 +1,151 @@
+package gonedrive
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Sentinel errors for common API failures.
+// Use errors.Is to check an *ErrorResponse against these.
+var (
+	ErrNotFound           = errors.New("item not found")
+	ErrUnauthorized       = errors.New("unauthorized")
+	ErrAccessDenied       = errors.New("access denied")
+	ErrThrottled          = errors.New("request throttled")
+	ErrNameAlreadyExists  = errors.New("name already exists")
+	ErrQuotaExceeded      = errors.New("quota exceeded")
+	ErrPreconditionFailed = errors.New("precondition failed")
+)
+
+// Only this much of non-JSON error bodies is kept.
+const errorBodyLimit = 512
+
+// Error response from the MS graph API.
+type ErrorResponse struct {
+	Outer struct {
+		Code    string     `json:"code"`
+		Message string     `json:"message"`
+		Inner   InnerError `json:"innerError"`
+	} `json:"error"`
+
+	// HTTP status code of the response.
+	StatusCode int `json:"-"`
+
+	// Method and URL of the failed request.
+	// The query is left out of the URL, as it may contain credentials.
+	Method string `json:"-"`
+	URL    string `json:"-"`
+
+	// Parsed Retry-After header, 0 if there was none.
+	RetryAfter time.Duration `json:"-"`
+
+	// Start of the response body.
+	// Useful when the body isn't JSON, such as error pages from proxies.
+	Body string `json:"-"`
+}
+
+// Details about an error, with a more specific code than the outer error.
+// These can be nested several levels deep.
+type InnerError struct {
+	Code            string      `json:"code"`
+	Date            string      `json:"date"`
+	RequestID       string      `json:"request-id"`
+	ClientRequestId string      `json:"client-request-id"`
+	Inner           *InnerError `json:"innerError"`
+}
+
+func newErrorResponse(response *http.Response, body []byte) *ErrorResponse {
+	err := &ErrorResponse{
+		StatusCode: response.StatusCode,
+		Body:       string(body[:min(len(body), errorBodyLimit)]),
+	}
+	json.Unmarshal(body, err)
+	if response.Request != nil {
+		err.Method = response.Request.Method
+		err.URL = logURL(response.Request.URL)
+	}
+	err.RetryAfter, _ = parseRetryAfter(response.Header.Get("Retry-After"))
+	return err
+}
+
+func (err *ErrorResponse) Error() string {
+	msg := fmt.Sprintf("%s %s: %d", err.Method, err.URL, err.StatusCode)
+	if err.Outer.Code == "" {
+		return msg + ": " + strings.TrimSpace(err.Body)
+	}
+	msg += fmt.Sprintf(" %s: %s", strings.Join(err.Codes(), "/"), err.Outer.Message)
+	if err.Outer.Inner.RequestID != "" {
+		msg += fmt.Sprintf(" (request-id %s)", err.Outer.Inner.RequestID)
+	}
+	return msg
+}
+
+// Returns the error code, followed by any nested inner error codes.
+func (err *ErrorResponse) Codes() []string {
+	codes := []string{}
+	if err.Outer.Code != "" {
+		codes = append(codes, err.Outer.Code)
+	}
+	for inner := &err.Outer.Inner; inner != nil; inner = inner.Inner {
+		if inner.Code != "" {
+			codes = append(codes, inner.Code)
+		}
+	}
+	return codes
+}
+
+// Reports whether code is the error code, or one of the inner error codes.
+func (err *ErrorResponse) HasCode(code string) bool {
+	return slices.Contains(err.Codes(), code)
+}
+
+// Matches the error against the sentinel errors of this package.
+func (err *ErrorResponse) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return err.StatusCode == http.StatusNotFound || err.HasCode("itemNotFound")
+	case ErrUnauthorized:
+		return err.StatusCode == http.StatusUnauthorized || err.HasCode("unauthenticated")
+	case ErrAccessDenied:
+		return err.StatusCode == http.StatusForbidden || err.HasCode("accessDenied")
+	case ErrThrottled:
+		return err.StatusCode == http.StatusTooManyRequests || err.HasCode("activityLimitReached")
+	case ErrNameAlreadyExists:
+		return err.HasCode("nameAlreadyExists")
+	case ErrQuotaExceeded:
+		return err.StatusCode == http.StatusInsufficientStorage || err.HasCode("quotaLimitReached")
+	case ErrPreconditionFailed:
+		return err.StatusCode == http.StatusPreconditionFailed || err.HasCode("preconditionFailed")
+	}
+	return false
+}
+
+// Error response from the OAuth endpoints.
+type AuthError struct {
+	StatusCode    int    `json:"-"`
+	Code          string `json:"error"`
+	Description   string `json:"error_description"`
+	ErrorCodes    []int  `json:"error_codes"`
+	TraceID       string `json:"trace_id"`
+	CorrelationID string `json:"correlation_id"`
+}
+
+func newAuthError(response *http.Response, body []byte) *AuthError {
+	err := &AuthError{StatusCode: response.StatusCode}
+	if json.Unmarshal(body, err) != nil || err.Code == "" {
+		err.Code = response.Status
+	}
+	return err
+}
+
+func (err *AuthError) Error() string {
+	if err.Description == "" {
+		return err.Code
+	}
+	return fmt.Sprintf("%s: %s", err.Code, err.Description)
+}
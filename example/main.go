@@ -1,10 +1,11 @@
 package main
 
 import (
+	"context"
 	_ "embed"
-	"encoding/json"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -16,12 +17,14 @@ import (
 var clientId string
 
 func main() {
-	dat, _ := os.ReadFile("graphtoken.json")
-	t := &gonedrive.GraphToken{}
-	json.Unmarshal(dat, t)
+	t, err := gonedrive.LoadToken(gonedrive.NewFileTokenStore("graphtoken.json"))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
 
 	fmt.Println("Authenticating...")
-	t, err := gonedrive.CreateAccess(
+	t, err = gonedrive.CreateAccess(
 		clientId,
 		"http://127.0.0.1:8090/auth",
 		t,
@@ -31,8 +34,10 @@ func main() {
 		return
 	}
 
-	//Sync files
-	err = t.SyncFolder("Musik/mp3tag", "songs", Mp3Filter, EventHandler)
+	//Sync files, until interrupted
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	err = t.SyncFolderContext(ctx, "Musik/mp3tag", "songs", Mp3Filter, EventHandler)
 	if err != nil {
 		fmt.Println(err)
 		return
@@ -0,0 +1,82 @@
+package gonedrive
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+var ErrNotFolder = errors.New("item is not a folder")
+
+// Creates a folder called name in parent.
+// If an item with that name already exists, conflictBehaviour decides what happens.
+// With ConflictBehaviour_Fail, the error matches ErrNameAlreadyExists.
+func (c *Client) CreateFolder(ctx context.Context, parent ItemRef, name string, conflictBehaviour ConflictBehaviour) (*DriveItem, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+	endpoint, err := c.itemEndpoint(parent, "children")
+	if err != nil {
+		return nil, err
+	}
+
+	// Create request body
+	body := map[string]any{
+		"name":   name,
+		"folder": map[string]any{},
+	}
+	if conflictBehaviour != "" {
+		body["@microsoft.graph.conflictBehavior"] = conflictBehaviour
+	}
+	requestData, _ := json.Marshal(body)
+
+	// Create folder
+	request, err := c.BuildRequestContext(ctx, "POST", endpoint, bytes.NewReader(requestData))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	return SendRequest[DriveItem](c, request)
+}
+
+// Creates the folder at ref, along with any missing parent folders.
+// Folders that already exist are left alone, so this can safely be called
+// more than once. Returns the folder at ref.
+//
+// Fails with ErrNotFolder if a file is in the way.
+func (c *Client) MkdirAll(ctx context.Context, ref ItemRef) (*DriveItem, error) {
+	// Already there?
+	item, err := c.GetDriveItem(ctx, ref, nil)
+	names := splitPath(ref.Path)
+	if err == nil || !errors.Is(err, ErrNotFound) || len(names) == 0 {
+		// The base itself cannot be created
+		return checkFolder(ref, item, err)
+	}
+
+	// Walk down from the base, creating missing folders
+	current := ItemRef{Drive: ref.Drive, ID: ref.ID, Special: ref.Special}
+	for _, name := range names {
+		item, err = c.CreateFolder(ctx, current, name, ConflictBehaviour_Fail)
+		if errors.Is(err, ErrNameAlreadyExists) {
+			item, err = c.GetDriveItem(ctx, current.Child(name), nil)
+		}
+		if item, err = checkFolder(current.Child(name), item, err); err != nil {
+			return nil, err
+		}
+		current = ItemRef{Drive: ref.Drive, ID: item.Id}
+	}
+	return item, nil
+}
+
+// Turns a non-folder item into an error.
+func checkFolder(ref ItemRef, item *DriveItem, err error) (*DriveItem, error) {
+	if err != nil {
+		return nil, err
+	}
+	if !item.IsDir() {
+		return nil, fmt.Errorf("%w: %s", ErrNotFolder, ref)
+	}
+	return item, nil
+}
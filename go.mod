@@ -1,6 +1,6 @@
 module github.com/sukus21/gonedrive
 
-go 1.22
+go 1.23
 
 require github.com/pkg/browser v0.0.0-20240102092130-5ac0b6a4141c
 
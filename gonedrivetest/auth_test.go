@@ -0,0 +1,281 @@
+package gonedrivetest_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sukus21/gonedrive"
+	"github.com/sukus21/gonedrive/gonedrivetest"
+)
+
+// Token with the fake server's config, but no credentials yet.
+func configToken(s *gonedrivetest.Server) *gonedrive.GraphToken {
+	token := gonedrive.NewGraphToken(gonedrive.TokenConfig{AuthorityHost: s.URL, GraphURL: s.URL})
+	return token
+}
+
+func TestDeviceCodeLogin(t *testing.T) {
+	s := gonedrivetest.NewServer()
+	defer s.Close()
+	s.AddFile("a.txt", []byte("a"))
+
+	var prompted *gonedrive.DeviceCode
+	token, err := gonedrive.CreateAccessDeviceCodeContext(
+		context.Background(),
+		gonedrivetest.ClientID,
+		configToken(s),
+		func(code *gonedrive.DeviceCode) { prompted = code },
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prompted == nil || prompted.UserCode != "FAKECODE" {
+		t.Errorf("prompted with %+v", prompted)
+	}
+	if token.AccessToken == "" || token.RefreshToken == "" {
+		t.Errorf("got token %+v", token)
+	}
+
+	// No refresh attempt without a refresh token
+	if n := countRequests(s, "POST", "/devicecode"); n != 1 {
+		t.Errorf("requested %d device codes, want 1", n)
+	}
+	if n := countRequests(s, "POST", "/token"); n != 1 {
+		t.Errorf("sent %d token requests, want 1", n)
+	}
+
+	// Token works against the API
+	c := gonedrive.NewClient(gonedrive.WithTokenSource(token))
+	if _, err := c.GetDriveItem(context.Background(), gonedrive.ItemByPath("a.txt"), nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDeviceCodeCancel(t *testing.T) {
+	s := gonedrivetest.NewServer()
+	defer s.Close()
+
+	// Give up as soon as the code is shown
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	start := time.Now()
+	_, err := gonedrive.CreateAccessDeviceCodeContext(ctx, gonedrivetest.ClientID, configToken(s), func(code *gonedrive.DeviceCode) {
+		cancel()
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("took %s to give up", elapsed)
+	}
+	if n := countRequests(s, "POST", "/token"); n != 0 {
+		t.Errorf("sent %d token requests, want 0", n)
+	}
+}
+
+// Transport that records the forms posted to the token endpoint.
+type tokenFormTransport struct {
+	mux   sync.Mutex
+	forms []url.Values
+}
+
+func (transport *tokenFormTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if strings.HasSuffix(request.URL.Path, "/token") {
+		body, err := io.ReadAll(request.Body)
+		if err != nil {
+			return nil, err
+		}
+		form, _ := url.ParseQuery(string(body))
+		transport.mux.Lock()
+		transport.forms = append(transport.forms, form)
+		transport.mux.Unlock()
+		request.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	return http.DefaultTransport.RoundTrip(request)
+}
+
+// Follows the redirect back to the app, as the browser would after sign-in.
+func finishBrowserLogin(redirectURI string, query url.Values) error {
+	response, err := http.Get(redirectURI + "?" + query.Encode())
+	if err != nil {
+		return err
+	}
+	return response.Body.Close()
+}
+
+// Signs in with the browser flow, answering the sign-in page with answer.
+// Returns the token, the query of the sign-in page and the forms posted to the token endpoint.
+func browserLogin(t *testing.T, s *gonedrivetest.Server, answer func(authorize url.Values) url.Values) (*gonedrive.GraphToken, url.Values, []url.Values, error) {
+	t.Helper()
+	transport := &tokenFormTransport{}
+	refresh := configToken(s)
+	refresh.SetHTTPClient(&http.Client{Transport: transport})
+
+	var authorize url.Values
+	token, err := gonedrive.CreateAccessBrowser(
+		context.Background(),
+		gonedrivetest.ClientID,
+		"http://127.0.0.1/callback",
+		refresh,
+		gonedrive.BrowserLoginParams{OpenURL: func(signInURL string) error {
+			u, err := url.Parse(signInURL)
+			if err != nil {
+				return err
+			}
+			authorize = u.Query()
+			return finishBrowserLogin(authorize.Get("redirect_uri"), answer(authorize))
+		}},
+	)
+	return token, authorize, transport.forms, err
+}
+
+func TestBrowserLogin(t *testing.T) {
+	s := gonedrivetest.NewServer()
+	defer s.Close()
+
+	token, authorize, forms, err := browserLogin(t, s, func(authorize url.Values) url.Values {
+		return url.Values{"code": {"fake-code"}, "state": {authorize.Get("state")}}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken == "" || token.RefreshToken == "" {
+		t.Errorf("got token %+v", token)
+	}
+
+	// Ephemeral port in the redirect URI
+	redirect, err := url.Parse(authorize.Get("redirect_uri"))
+	if err != nil || redirect.Hostname() != "127.0.0.1" || redirect.Path != "/callback" {
+		t.Errorf("redirect URI is %q", authorize.Get("redirect_uri"))
+	}
+	if port, _ := strconv.Atoi(redirect.Port()); port == 0 {
+		t.Errorf("redirect URI %q has no port", redirect)
+	}
+
+	// State and PKCE
+	if len(authorize.Get("state")) < 32 || authorize.Get("code_challenge_method") != "S256" {
+		t.Errorf("sign-in page query is %v", authorize)
+	}
+	if len(forms) != 1 {
+		t.Fatalf("sent %d token requests, want 1", len(forms))
+	}
+	form := forms[0]
+	challenge := sha256.Sum256([]byte(form.Get("code_verifier")))
+	if form.Get("code_verifier") == "" || base64.RawURLEncoding.EncodeToString(challenge[:]) != authorize.Get("code_challenge") {
+		t.Errorf("code verifier %q does not match challenge %q", form.Get("code_verifier"), authorize.Get("code_challenge"))
+	}
+	if form.Get("code") != "fake-code" || form.Get("redirect_uri") != authorize.Get("redirect_uri") {
+		t.Errorf("token request form is %v", form)
+	}
+
+	// Each login gets its own state
+	_, again, _, err := browserLogin(t, s, func(authorize url.Values) url.Values {
+		return url.Values{"code": {"fake-code"}, "state": {authorize.Get("state")}}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Get("state") == authorize.Get("state") || again.Get("code_challenge") == authorize.Get("code_challenge") {
+		t.Error("state and code challenge were reused")
+	}
+}
+
+func TestBrowserLoginWrongState(t *testing.T) {
+	s := gonedrivetest.NewServer()
+	defer s.Close()
+
+	_, _, forms, err := browserLogin(t, s, func(authorize url.Values) url.Values {
+		return url.Values{"code": {"fake-code"}, "state": {"forged"}}
+	})
+	if err == nil {
+		t.Fatal("login with the wrong state succeeded")
+	}
+	if len(forms) != 0 {
+		t.Errorf("sent %d token requests, want 0", len(forms))
+	}
+}
+
+func TestTokenStoreSavesRefresh(t *testing.T) {
+	s := gonedrivetest.NewServer()
+	defer s.Close()
+	s.AddFile("a.txt", []byte("a"))
+	ctx := context.Background()
+
+	// Nothing saved yet
+	store := gonedrive.NewMemoryTokenStore()
+	if _, err := store.Load(); !errors.Is(err, gonedrive.ErrNoToken) {
+		t.Fatalf("empty store returned %v, want ErrNoToken", err)
+	}
+
+	// Refreshing saves the new token
+	token := s.Token()
+	token.SetStore(store)
+	old := token.AccessToken
+	s.ExpireTokens()
+	c := gonedrive.NewClient(gonedrive.WithTokenSource(token))
+	if _, err := c.GetDriveItem(ctx, gonedrive.ItemByPath("a.txt"), nil); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.AccessToken == old || saved.AccessToken != token.AccessToken || saved.RefreshToken == "" {
+		t.Errorf("saved access token %q, want %q", saved.AccessToken, token.AccessToken)
+	}
+
+	// Saved token can be used as is, config included
+	loaded, err := gonedrive.LoadToken(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.GraphURL != s.URL {
+		t.Errorf("loaded token has Graph URL %q, want %q", loaded.GraphURL, s.URL)
+	}
+	if _, err := gonedrive.NewClient(gonedrive.WithTokenSource(loaded)).GetDriveItem(ctx, gonedrive.ItemByPath("a.txt"), nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConcurrentRefresh(t *testing.T) {
+	s := gonedrivetest.NewServer()
+	defer s.Close()
+	s.AddFile("a.txt", []byte("a"))
+
+	// About to expire, so it is refreshed before use
+	token := s.Token()
+	token.ExpiresAt = time.Now().Add(time.Minute)
+	old := token.AccessToken
+	c := s.NewClient(gonedrive.WithTokenSource(token))
+
+	const requests = 20
+	errs := make(chan error, requests)
+	for range requests {
+		go func() {
+			_, err := c.GetDriveItem(context.Background(), gonedrive.ItemByPath("a.txt"), nil)
+			errs <- err
+		}()
+	}
+	for range requests {
+		if err := <-errs; err != nil {
+			t.Error(err)
+		}
+	}
+	if n := countRequests(s, "POST", "/token"); n != 1 {
+		t.Errorf("sent %d token requests, want 1", n)
+	}
+	if token.AccessToken == old {
+		t.Error("token was not refreshed")
+	}
+}
@@ -0,0 +1,110 @@
+package gonedrivetest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Number of times a copy monitor reports progress before the copy completes.
+const copyPolls = 2
+
+// A copy in progress.
+// The copy is made right away, but the monitor pretends it takes a while.
+type copyMonitor struct {
+	result *item
+	polls  int
+}
+
+func (s *Server) handleCopy(w http.ResponseWriter, r *http.Request, src *item) {
+	var body struct {
+		ParentReference *struct {
+			DriveID string `json:"driveId"`
+			ID      string `json:"id"`
+		} `json:"parentReference"`
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.ParentReference == nil {
+		writeError(w, http.StatusBadRequest, "invalidRequest", "A parent reference is required.")
+		return
+	}
+
+	// Where to?
+	parent := s.items[body.ParentReference.ID]
+	if parent == nil || !parent.folder || (body.ParentReference.DriveID != "" && parent.drive.id != body.ParentReference.DriveID) {
+		writeError(w, http.StatusNotFound, "itemNotFound", "The destination could not be found.")
+		return
+	}
+	if parent.isBelow(src) {
+		writeError(w, http.StatusBadRequest, "invalidRequest", "An item cannot be copied into itself.")
+		return
+	}
+	name := src.name
+	if body.Name != "" {
+		name = body.Name
+	}
+	if parent.child(name) != nil {
+		writeError(w, http.StatusConflict, "nameAlreadyExists", "An item with the same name already exists.")
+		return
+	}
+
+	// Copy, and hand out a monitor
+	id := s.newID()
+	s.monitors[id] = &copyMonitor{result: s.copyTree(src, parent, name)}
+	w.Header().Set("Location", s.URL+"/monitor/"+url.PathEscape(id))
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// Copies src and everything below it into parent.
+func (s *Server) copyTree(src *item, parent *item, name string) *item {
+	now := time.Now()
+	it := &item{
+		id:          s.newID(),
+		name:        name,
+		description: src.description,
+		folder:      src.folder,
+		content:     append([]byte{}, src.content...),
+		created:     now,
+		modified:    src.modified,
+	}
+	s.items[it.id] = it
+	parent.addChild(it)
+	parent.touch(now)
+	for _, child := range src.children {
+		s.copyTree(child, it, child.name)
+	}
+	return it
+}
+
+func (s *Server) handleMonitor(w http.ResponseWriter, r *http.Request, escapedID string) {
+	// Like the real thing, monitors must be polled without a token
+	if r.Header.Get("Authorization") != "" {
+		writeError(w, http.StatusUnauthorized, "unauthenticated", "Monitor URLs do not accept access tokens.")
+		return
+	}
+
+	id, _ := url.PathUnescape(escapedID)
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	monitor := s.monitors[id]
+	if monitor == nil {
+		writeError(w, http.StatusNotFound, "itemNotFound", "The monitor could not be found.")
+		return
+	}
+
+	// Still going?
+	if monitor.polls < copyPolls {
+		monitor.polls++
+		writeJSON(w, http.StatusAccepted, map[string]any{
+			"status":             "inProgress",
+			"percentageComplete": float64(monitor.polls) * 100 / (copyPolls + 1),
+		})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"status":             "completed",
+		"percentageComplete": 100,
+		"resourceId":         monitor.result.id,
+	})
+}
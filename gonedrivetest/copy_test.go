@@ -0,0 +1,107 @@
+package gonedrivetest_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sukus21/gonedrive"
+	"github.com/sukus21/gonedrive/gonedrivetest"
+)
+
+var resourceIDField = regexp.MustCompile(`,?"resourceId":"[^"]*"`)
+
+// Transport that removes the resourceId from copy monitor responses.
+type noResourceIDTransport struct{}
+
+func (noResourceIDTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	response, err := http.DefaultTransport.RoundTrip(request)
+	if err != nil || !strings.HasPrefix(response.Request.URL.Path, "/monitor/") {
+		return response, err
+	}
+	body, err := io.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	body = resourceIDField.ReplaceAll(body, nil)
+	response.Body = io.NopCloser(bytes.NewReader(body))
+	response.ContentLength = int64(len(body))
+	response.Header.Del("Content-Length")
+	return response, nil
+}
+
+func TestCopyItemToOtherDrive(t *testing.T) {
+	s := gonedrivetest.NewServer()
+	defer s.Close()
+	s.AddFile("src/a.txt", []byte("a"))
+	s.AddFile("src/sub/b.txt", []byte("b"))
+	d := s.AddDrive("users/bob", "bob-drive", "OneDrive")
+	d.AddFolder("dest")
+	c := s.NewClient()
+	ctx := context.Background()
+
+	op, err := c.CopyItem(ctx, gonedrive.ItemByPath("src"), gonedrive.ItemByPath("dest").OnDrive(d.Ref()), "copied")
+	if err != nil {
+		t.Fatal(err)
+	}
+	op.PollInterval = time.Millisecond
+	statuses := []gonedrive.CopyStatus{}
+	op.OnProgress = func(status *gonedrive.CopyStatus) {
+		statuses = append(statuses, *status)
+	}
+	item, err := op.Wait(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Progress was reported until done
+	if len(statuses) < 2 {
+		t.Fatalf("got %d progress callbacks, want at least 2", len(statuses))
+	}
+	for i, status := range statuses {
+		last := i == len(statuses)-1
+		if status.Done() != last || (i > 0 && status.PercentageComplete < statuses[i-1].PercentageComplete) {
+			t.Errorf("status %d of %d is %+v", i+1, len(statuses), status)
+		}
+	}
+	if final := statuses[len(statuses)-1]; final.Status != "completed" || final.PercentageComplete != 100 {
+		t.Errorf("final status is %+v", final)
+	}
+
+	// The copy is in the other drive, original untouched
+	if item.Name != "copied" || !item.IsDir() || item.ParentReference == nil || item.ParentReference.DriveId != d.ID() {
+		t.Errorf("copied item is %+v", item)
+	}
+	if content, ok := d.ReadFile("dest/copied/sub/b.txt"); !ok || string(content) != "b" {
+		t.Errorf("copied file has content %q", content)
+	}
+	if s.Item("src/sub/b.txt") == nil {
+		t.Error("original was removed")
+	}
+}
+
+func TestCopyItemNoResourceID(t *testing.T) {
+	s := gonedrivetest.NewServer()
+	defer s.Close()
+	s.AddFile("a.txt", []byte("a"))
+	s.AddFolder("dest")
+	c := s.NewClient(gonedrive.WithHTTPClient(&http.Client{Transport: noResourceIDTransport{}}))
+	ctx := context.Background()
+
+	op, err := c.CopyItem(ctx, gonedrive.ItemByPath("a.txt"), gonedrive.ItemByPath("dest"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	op.PollInterval = time.Millisecond
+	item, err := op.Wait(ctx)
+	if !errors.Is(err, gonedrive.ErrCopyFailed) || item != nil {
+		t.Errorf("got %+v, %v; want ErrCopyFailed", item, err)
+	}
+}
@@ -0,0 +1,113 @@
+package gonedrivetest
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// An item in the recycle bin.
+type recycledItem struct {
+	it     *item
+	parent *item
+}
+
+// Moves an item and everything below it out of the drive.
+func (s *Server) unregister(it *item) {
+	delete(s.items, it.id)
+	for _, child := range it.children {
+		s.unregister(child)
+	}
+}
+
+// Moves an item and everything below it back into the drive.
+func (s *Server) register(it *item) {
+	s.items[it.id] = it
+	for _, child := range it.children {
+		s.register(child)
+	}
+}
+
+// Reports whether the If-Match header of the request matches the item.
+func ifMatch(r *http.Request, it *item) bool {
+	etag := r.Header.Get("If-Match")
+	return etag == "" || etag == "*" || etag == it.etag()
+}
+
+func (s *Server) handleDelete(w http.ResponseWriter, r *http.Request, it *item, permanent bool) {
+	if it.isRoot() {
+		writeError(w, http.StatusForbidden, "accessDenied", "The root cannot be deleted.")
+		return
+	}
+	if permanent && it.drive.driveType == "personal" {
+		writeError(w, http.StatusBadRequest, "invalidRequest", "Permanent delete is not supported on this drive.")
+		return
+	}
+	if !ifMatch(r, it) {
+		writeError(w, http.StatusPreconditionFailed, "preconditionFailed", "The resource has changed since the caller last read it.")
+		return
+	}
+
+	// Gone for good?
+	if permanent {
+		s.removeItem(it)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	// Into the recycle bin
+	parent := it.parent
+	parent.touch(time.Now())
+	parent.removeChild(it)
+	s.unregister(it)
+	s.recycled[it.id] = &recycledItem{it: it, parent: parent}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleRestore(w http.ResponseWriter, r *http.Request, d *Drive, addr *address) {
+	var body struct {
+		ParentReference *struct {
+			ID string `json:"id"`
+		} `json:"parentReference"`
+		Name string `json:"name"`
+	}
+	json.NewDecoder(r.Body).Decode(&body)
+
+	// Only deleted items can be restored
+	recycled := s.recycled[addr.base]
+	if recycled == nil || len(addr.path) != 0 || recycled.it.drive != d {
+		writeError(w, http.StatusNotFound, "itemNotFound", "The item is not in the recycle bin.")
+		return
+	}
+	if d.driveType != "personal" {
+		writeError(w, http.StatusBadRequest, "invalidRequest", "Restore is not supported on this drive.")
+		return
+	}
+
+	// Where to?
+	parent := recycled.parent
+	if body.ParentReference != nil {
+		parent = s.items[body.ParentReference.ID]
+	}
+	if parent == nil || parent.drive != d || !parent.folder {
+		writeError(w, http.StatusNotFound, "itemNotFound", "The parent folder could not be found.")
+		return
+	}
+	it := recycled.it
+	name := it.name
+	if body.Name != "" {
+		name = body.Name
+	}
+	if parent.child(name) != nil {
+		writeError(w, http.StatusConflict, "nameAlreadyExists", "An item with the same name already exists.")
+		return
+	}
+
+	// Put it back
+	delete(s.recycled, it.id)
+	it.name = name
+	parent.addChild(it)
+	parent.touch(time.Now())
+	s.register(it)
+	writeJSON(w, http.StatusOK, it.json(s))
+}
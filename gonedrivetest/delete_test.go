@@ -0,0 +1,93 @@
+package gonedrivetest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sukus21/gonedrive"
+	"github.com/sukus21/gonedrive/gonedrivetest"
+)
+
+func TestDeleteAndRestore(t *testing.T) {
+	s := gonedrivetest.NewServer()
+	defer s.Close()
+	stale := s.AddFile("docs/a.txt", []byte("v1"))
+	s.AddFile("docs/a.txt", []byte("v2"))
+	other := s.AddFolder("other")
+	c := s.NewClient()
+	ctx := context.Background()
+	ref := gonedrive.ItemByID(stale.Id)
+
+	// Changed since it was read
+	if err := c.DeleteItem(ctx, ref, stale.Etag); !errors.Is(err, gonedrive.ErrPreconditionFailed) {
+		t.Fatalf("got %v, want ErrPreconditionFailed", err)
+	}
+	if s.Item("docs/a.txt") == nil {
+		t.Fatal("item was deleted anyway")
+	}
+
+	// Up to date
+	if err := c.DeleteItem(ctx, ref, s.Item("docs/a.txt").Etag); err != nil {
+		t.Fatal(err)
+	}
+	if s.Item("docs/a.txt") != nil {
+		t.Fatal("item was not deleted")
+	}
+	if _, err := c.GetDriveItem(ctx, ref, nil); !errors.Is(err, gonedrive.ErrNotFound) {
+		t.Errorf("deleted item lookup returned %v, want ErrNotFound", err)
+	}
+
+	// Back where it was
+	restored, err := c.Restore(ctx, ref, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.Id != stale.Id || restored.Name != "a.txt" {
+		t.Errorf("restored %+v", restored)
+	}
+	if content, ok := s.ReadFile("docs/a.txt"); !ok || string(content) != "v2" {
+		t.Errorf("restored file has content %q", content)
+	}
+	if _, err := c.Restore(ctx, ref, "", ""); !errors.Is(err, gonedrive.ErrNotFound) {
+		t.Errorf("restoring twice returned %v, want ErrNotFound", err)
+	}
+
+	// Somewhere else, under another name
+	if err := c.DeleteItem(ctx, ref, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Restore(ctx, ref, other.Id, "b.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if content, ok := s.ReadFile("other/b.txt"); !ok || string(content) != "v2" {
+		t.Errorf("restored file has content %q", content)
+	}
+}
+
+func TestPermanentDelete(t *testing.T) {
+	s := gonedrivetest.NewServer()
+	defer s.Close()
+	s.AddFile("a.txt", []byte("a"))
+	d := s.AddDrive("users/bob", "bob-drive", "OneDrive")
+	item := d.AddFile("a.txt", []byte("a"))
+	c := s.NewClient()
+	ctx := context.Background()
+
+	// Not on personal drives
+	if err := c.PermanentDelete(ctx, gonedrive.ItemByPath("a.txt"), ""); err == nil {
+		t.Error("permanent delete on a personal drive succeeded")
+	}
+
+	// Cannot be restored
+	bob := c.OnDrive(d.Ref())
+	if err := bob.PermanentDelete(ctx, gonedrive.ItemByPath("a.txt"), ""); err != nil {
+		t.Fatal(err)
+	}
+	if d.Item("a.txt") != nil {
+		t.Error("item was not deleted")
+	}
+	if _, err := bob.Restore(ctx, gonedrive.ItemByID(item.Id), "", ""); !errors.Is(err, gonedrive.ErrNotFound) {
+		t.Errorf("restore returned %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,133 @@
+package gonedrivetest_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sukus21/gonedrive"
+	"github.com/sukus21/gonedrive/gonedrivetest"
+)
+
+// Transport that records the Range header of every download request.
+type rangeTransport struct {
+	mux    sync.Mutex
+	ranges []string
+}
+
+func (transport *rangeTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if strings.HasPrefix(request.URL.Path, "/download/") {
+		transport.mux.Lock()
+		transport.ranges = append(transport.ranges, request.Header.Get("Range"))
+		transport.mux.Unlock()
+	}
+	return http.DefaultTransport.RoundTrip(request)
+}
+
+// Content that differs at every offset, so misplaced bytes are noticed.
+func downloadContent(size int) []byte {
+	content := make([]byte, size)
+	for i := range content {
+		content[i] = byte(i*31 + i/251)
+	}
+	return content
+}
+
+func TestRemoteFileReadAt(t *testing.T) {
+	s := gonedrivetest.NewServer()
+	defer s.Close()
+	content := downloadContent(10000)
+	s.AddFile("file.bin", content)
+
+	f, err := s.NewClient().OpenFile(context.Background(), gonedrive.ItemByPath("file.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.ReadAhead = 1000
+	if f.Size() != int64(len(content)) {
+		t.Fatalf("size is %d, want %d", f.Size(), len(content))
+	}
+
+	// Reads within and across the read-ahead buffer
+	for _, off := range []int{0, 10, 990, 5000, 9990} {
+		p := make([]byte, 20)
+		n, err := f.ReadAt(p, int64(off))
+		want := content[off:min(off+20, len(content))]
+		if !bytes.Equal(p[:n], want) {
+			t.Errorf("ReadAt(%d) read %x, want %x", off, p[:n], want)
+		}
+		if (err == io.EOF) != (off+20 > len(content)) || (err != nil && err != io.EOF) {
+			t.Errorf("ReadAt(%d) returned %v", off, err)
+		}
+	}
+	if _, err := f.ReadAt(make([]byte, 1), int64(len(content))); err != io.EOF {
+		t.Errorf("ReadAt past the end returned %v, want io.EOF", err)
+	}
+
+	// Seek from the end, then read the rest
+	if pos, err := f.Seek(-100, io.SeekEnd); err != nil || pos != int64(len(content)-100) {
+		t.Fatalf("Seek returned %d, %v", pos, err)
+	}
+	tail, err := io.ReadAll(f)
+	if err != nil || !bytes.Equal(tail, content[len(content)-100:]) {
+		t.Errorf("read %d bytes after seeking (%v)", len(tail), err)
+	}
+}
+
+func TestDownloadToFile(t *testing.T) {
+	content := downloadContent(200000)
+	wrong := bytes.Clone(content[:50000])
+	wrong[100] ^= 0xFF
+	tests := []struct {
+		name   string
+		local  []byte
+		fault  bool
+		ranges []string
+	}{
+		{"new", nil, false, []string{"bytes=0-"}},
+		{"resume", content[:50000], false, []string{"bytes=50000-"}},
+		{"wrong prefix", wrong, false, []string{"bytes=50000-", "bytes=0-"}},
+		{"too long", append(bytes.Clone(content), 'x'), false, []string{"bytes=0-"}},
+		{"truncated", nil, true, []string{"bytes=0-", "bytes=100000-"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := gonedrivetest.NewServer()
+			defer s.Close()
+			s.AddFile("file.bin", content)
+			if test.fault {
+				s.InjectFault(gonedrivetest.Fault{Path: "/download/", Truncate: true, Times: 1})
+			}
+			localPath := filepath.Join(t.TempDir(), "file.bin")
+			if test.local != nil {
+				if err := os.WriteFile(localPath, test.local, 0666); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			transport := &rangeTransport{}
+			c := s.NewClient(gonedrive.WithHTTPClient(&http.Client{Transport: transport}))
+			item, err := c.DownloadToFile(context.Background(), gonedrive.ItemByPath("file.bin"), localPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if item.Size != int64(len(content)) {
+				t.Errorf("item has size %d", item.Size)
+			}
+			local, err := os.ReadFile(localPath)
+			if err != nil || !bytes.Equal(local, content) {
+				t.Errorf("local file has %d bytes and does not match (%v)", len(local), err)
+			}
+			if !slices.Equal(transport.ranges, test.ranges) {
+				t.Errorf("requested ranges %q, want %q", transport.ranges, test.ranges)
+			}
+		})
+	}
+}
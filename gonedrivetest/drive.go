@@ -0,0 +1,207 @@
+package gonedrivetest
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sukus21/gonedrive"
+	"github.com/sukus21/gonedrive/quickxor"
+)
+
+// A drive served by the fake.
+// Use the methods to set up and inspect its contents.
+type Drive struct {
+	s         *Server
+	id        string
+	name      string
+	owner     string
+	driveType string
+	root      *item
+}
+
+// ID of the drive.
+func (d *Drive) ID() string {
+	return d.id
+}
+
+// Reference to the drive, for use with gonedrive.WithDrive and the like.
+func (d *Drive) Ref() gonedrive.DriveRef {
+	return gonedrive.DriveByID(d.id)
+}
+
+// Serializes the drive the same way the API does.
+func (d *Drive) json() map[string]any {
+	used := d.root.size()
+	return map[string]any{
+		"id":        d.id,
+		"name":      d.name,
+		"driveType": d.driveType,
+		"webUrl":    d.s.URL + "/web/" + d.id,
+		"quota": map[string]any{
+			"total":     driveQuota,
+			"used":      used,
+			"remaining": driveQuota - used,
+			"deleted":   0,
+			"state":     "normal",
+		},
+	}
+}
+
+// A file or folder in a fake drive.
+type item struct {
+	id          string
+	name        string
+	description string
+	drive       *Drive
+	parent      *item
+	children    []*item
+	folder      bool
+	content     []byte
+	created     time.Time
+	modified    time.Time
+	version     int
+}
+
+func (it *item) isRoot() bool {
+	return it.parent == nil
+}
+
+// Finds a direct child by name.
+// Like OneDrive, names are case insensitive.
+func (it *item) child(name string) *item {
+	for _, child := range it.children {
+		if strings.EqualFold(child.name, name) {
+			return child
+		}
+	}
+	return nil
+}
+
+// Path of the item, relative to the drive root.
+func (it *item) path() string {
+	if it.isRoot() {
+		return ""
+	}
+	return path.Join(it.parent.path(), it.name)
+}
+
+// Path of the parent folder, as used in parentReference.
+func (it *item) parentPath() string {
+	root := "/drive/root:"
+	if it.drive != it.drive.s.drive {
+		root = "/drives/" + it.drive.id + "/root:"
+	}
+	if it.parent.isRoot() {
+		return root
+	}
+	return root + "/" + it.parent.path()
+}
+
+func (it *item) etag() string {
+	return fmt.Sprintf("\"{%s},%d\"", it.id, it.version)
+}
+
+// Marks the item as modified.
+func (it *item) touch(now time.Time) {
+	it.modified = now
+	it.version++
+}
+
+func (it *item) size() int64 {
+	if !it.folder {
+		return int64(len(it.content))
+	}
+	size := int64(0)
+	for _, child := range it.children {
+		size += child.size()
+	}
+	return size
+}
+
+// Reports whether it is other, or somewhere below it.
+func (it *item) isBelow(other *item) bool {
+	for ; it != nil; it = it.parent {
+		if it == other {
+			return true
+		}
+	}
+	return false
+}
+
+// Adds a child, keeping children sorted by name.
+func (it *item) addChild(child *item) {
+	child.parent = it
+	child.drive = it.drive
+	i, _ := slices.BinarySearchFunc(it.children, child.name, func(c *item, name string) int {
+		return strings.Compare(strings.ToLower(c.name), strings.ToLower(name))
+	})
+	it.children = slices.Insert(it.children, i, child)
+}
+
+func (it *item) removeChild(child *item) {
+	it.children = slices.DeleteFunc(it.children, func(c *item) bool {
+		return c == child
+	})
+	child.parent = nil
+}
+
+// Serializes the item the same way the API does.
+func (it *item) json(s *Server) map[string]any {
+	out := map[string]any{
+		"id":                   it.id,
+		"name":                 it.name,
+		"size":                 it.size(),
+		"eTag":                 it.etag(),
+		"cTag":                 it.etag(),
+		"createdDateTime":      it.created.UTC().Format(time.RFC3339),
+		"lastModifiedDateTime": it.modified.UTC().Format(time.RFC3339),
+		"webUrl":               s.URL + "/web/" + it.id,
+	}
+	if it.description != "" {
+		out["description"] = it.description
+	}
+	if it.isRoot() {
+		out["root"] = map[string]any{}
+		out["parentReference"] = map[string]any{
+			"driveId":   it.drive.id,
+			"driveType": it.drive.driveType,
+		}
+	} else {
+		out["parentReference"] = map[string]any{
+			"id":        it.parent.id,
+			"driveId":   it.drive.id,
+			"driveType": it.drive.driveType,
+			"path":      it.parentPath(),
+		}
+	}
+
+	if it.folder {
+		out["folder"] = map[string]any{
+			"childCount": len(it.children),
+		}
+	} else {
+		sha1Sum := sha1.Sum(it.content)
+		sha256Sum := sha256.Sum256(it.content)
+		mimeType := mime.TypeByExtension(path.Ext(it.name))
+		if mimeType == "" {
+			mimeType = "application/octet-stream"
+		}
+		out["file"] = map[string]any{
+			"mimeType": mimeType,
+			"hashes": map[string]any{
+				"quickXorHash": quickxor.QuickXorHashBase64(it.content),
+				"sha1Hash":     strings.ToUpper(hex.EncodeToString(sha1Sum[:])),
+				"sha256Hash":   strings.ToUpper(hex.EncodeToString(sha256Sum[:])),
+			},
+		}
+		out["@content.downloadUrl"] = s.downloadURL(it)
+	}
+	return out
+}
@@ -0,0 +1,122 @@
+package gonedrivetest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// A failure to inject into requests made to the fake server.
+type Fault struct {
+	// Only requests with this method are affected.
+	// Empty matches any method.
+	Method string
+
+	// Only requests whose path contains this are affected.
+	// Empty matches any path.
+	Path string
+
+	// Respond with this status code, instead of handling the request.
+	StatusCode int
+
+	// Retry-After header sent along with StatusCode, in seconds.
+	RetryAfter int
+
+	// Handle the request as usual, but cut the response body off halfway
+	// and close the connection.
+	// If the response is a redirect, such as for "/content", the response
+	// it redirects to is cut off instead. File content itself is served from
+	// "/download/{id}", and uploads are sent to "/upload/{id}".
+	Truncate bool
+
+	// Number of requests to affect.
+	// 0 means all matching requests, until ClearFaults is called.
+	Times int
+}
+
+// Error codes sent along with injected status codes.
+var faultCodes = map[int]string{
+	http.StatusTooManyRequests:     "activityLimitReached",
+	http.StatusInternalServerError: "generalException",
+	http.StatusBadGateway:          "badGateway",
+	http.StatusServiceUnavailable:  "serviceNotAvailable",
+	http.StatusGatewayTimeout:      "gatewayTimeout",
+	http.StatusInsufficientStorage: "quotaLimitReached",
+}
+
+// Adds a fault.
+// Faults are checked in the order they were added, and only the first
+// matching fault is applied to a request.
+func (s *Server) InjectFault(fault Fault) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.faults = append(s.faults, &fault)
+}
+
+// Removes all faults.
+func (s *Server) ClearFaults() {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.faults = nil
+}
+
+// Finds the fault to apply to a request, if any.
+// Must be called with the server locked.
+func (s *Server) takeFault(r *http.Request) *Fault {
+	for i, fault := range s.faults {
+		if fault.Method != "" && fault.Method != r.Method {
+			continue
+		}
+		if !strings.Contains(r.URL.EscapedPath(), fault.Path) {
+			continue
+		}
+
+		// Used up?
+		if fault.Times > 0 {
+			fault.Times--
+			if fault.Times == 0 {
+				s.faults = append(s.faults[:i:i], s.faults[i+1:]...)
+			}
+		}
+		return fault
+	}
+	return nil
+}
+
+func (s *Server) serveFault(fault *Fault, w http.ResponseWriter, r *http.Request) {
+	if fault.Truncate {
+		rec := httptest.NewRecorder()
+		s.route(rec, r)
+		body := rec.Body.Bytes()
+		for key, values := range rec.Header() {
+			w.Header()[key] = values
+		}
+
+		// Truncate where the redirect leads instead
+		location, err := url.Parse(rec.Header().Get("Location"))
+		if rec.Code >= 300 && rec.Code < 400 && err == nil && location.Path != "" {
+			s.mux.Lock()
+			s.faults = append([]*Fault{{Path: location.EscapedPath(), Truncate: true, Times: 1}}, s.faults...)
+			s.mux.Unlock()
+			w.WriteHeader(rec.Code)
+			w.Write(body)
+			return
+		}
+
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		w.WriteHeader(rec.Code)
+		w.Write(body[:len(body)/2])
+		return
+	}
+
+	if fault.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(fault.RetryAfter))
+	}
+	code, ok := faultCodes[fault.StatusCode]
+	if !ok {
+		code = "generalException"
+	}
+	writeError(w, fault.StatusCode, code, "Injected fault.")
+}
@@ -0,0 +1,67 @@
+package gonedrivetest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sukus21/gonedrive"
+	"github.com/sukus21/gonedrive/gonedrivetest"
+)
+
+func TestMkdirAll(t *testing.T) {
+	s := gonedrivetest.NewServer()
+	defer s.Close()
+	c := s.NewClient()
+	ctx := context.Background()
+
+	// Creating twice gives the same folder
+	first, err := c.MkdirAll(ctx, gonedrive.ItemByPath("a/b/c"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := c.MkdirAll(ctx, gonedrive.ItemByPath("a/b/c"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !first.IsDir() || first.Name != "c" || first.Id != second.Id {
+		t.Errorf("got %+v and %+v", first, second)
+	}
+	if item := s.Item("a/b/c"); item == nil || item.Id != first.Id {
+		t.Errorf("server has %+v at a/b/c", item)
+	}
+
+	// Below an ID
+	base := s.AddFolder("base")
+	nested, err := c.MkdirAll(ctx, gonedrive.ItemByID(base.Id).Child("d/e"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item := s.Item("base/d/e"); item == nil || item.Id != nested.Id {
+		t.Errorf("server has %+v at base/d/e", item)
+	}
+}
+
+func TestMkdirAllErrors(t *testing.T) {
+	s := gonedrivetest.NewServer()
+	defer s.Close()
+	s.AddFile("x/file.txt", []byte("x"))
+	c := s.NewClient()
+	ctx := context.Background()
+
+	tests := []struct {
+		ref gonedrive.ItemRef
+		err error
+	}{
+		{gonedrive.ItemByPath("x/file.txt"), gonedrive.ErrNotFolder},
+		{gonedrive.ItemByPath("x/file.txt/sub"), gonedrive.ErrNotFolder},
+		{gonedrive.ItemByID("nope"), gonedrive.ErrNotFound},
+		{gonedrive.ItemByID("nope").Child("a"), gonedrive.ErrNotFound},
+	}
+	for _, test := range tests {
+		item, err := c.MkdirAll(ctx, test.ref)
+		if !errors.Is(err, test.err) || item != nil {
+			t.Errorf("MkdirAll(%s) = %+v, %v; want %v", test.ref, item, err, test.err)
+		}
+	}
+}
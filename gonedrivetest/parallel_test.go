@@ -0,0 +1,106 @@
+package gonedrivetest_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/sukus21/gonedrive"
+	"github.com/sukus21/gonedrive/gonedrivetest"
+)
+
+var quickXorField = regexp.MustCompile(`"quickXorHash":"[^"]*"`)
+
+// Transport that replaces the QuickXorHash of every item in API responses,
+// as if the content did not match what the API reported.
+type wrongHashTransport struct{}
+
+func (wrongHashTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	response, err := http.DefaultTransport.RoundTrip(request)
+	if err != nil || response.Header.Get("Content-Type") != "application/json" {
+		return response, err
+	}
+	body, err := io.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	body = quickXorField.ReplaceAll(body, []byte(`"quickXorHash":"AAAAAAAAAAAAAAAAAAAAAAAAAAA="`))
+	response.Body = io.NopCloser(bytes.NewReader(body))
+	response.ContentLength = int64(len(body))
+	response.Header.Del("Content-Length")
+	return response, nil
+}
+
+func TestDownloadParallel(t *testing.T) {
+	s := gonedrivetest.NewServer()
+	defer s.Close()
+	content := downloadContent(100000)
+	s.AddFile("file.bin", content)
+
+	// Some ranges break halfway
+	s.InjectFault(gonedrivetest.Fault{Path: "/download/", Truncate: true, Times: 3})
+	localPath := filepath.Join(t.TempDir(), "file.bin")
+	local, err := os.Create(localPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer local.Close()
+
+	item, err := s.NewClient().DownloadParallel(context.Background(), gonedrive.ItemByPath("file.bin"), local, gonedrive.ParallelDownloadParams{
+		ChunkSize:   7000,
+		Connections: 4,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.Size != int64(len(content)) {
+		t.Errorf("item has size %d", item.Size)
+	}
+	downloaded, err := os.ReadFile(localPath)
+	if err != nil || !bytes.Equal(downloaded, content) {
+		t.Errorf("local file has %d bytes and does not match (%v)", len(downloaded), err)
+	}
+	if n := countRequests(s, "GET", "/download/"); n != 15+3 {
+		t.Errorf("made %d range requests, want 18", n)
+	}
+}
+
+func TestDownloadParallelHashMismatch(t *testing.T) {
+	s := gonedrivetest.NewServer()
+	defer s.Close()
+	s.AddFile("file.bin", downloadContent(100000))
+	local, err := os.Create(filepath.Join(t.TempDir(), "file.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer local.Close()
+
+	c := s.NewClient(gonedrive.WithHTTPClient(&http.Client{Transport: wrongHashTransport{}}))
+	_, err = c.DownloadParallel(context.Background(), gonedrive.ItemByPath("file.bin"), local, gonedrive.ParallelDownloadParams{
+		ChunkSize:   7000,
+		Connections: 4,
+	})
+	if !errors.Is(err, gonedrive.ErrHashMismatch) {
+		t.Fatalf("got %v, want ErrHashMismatch", err)
+	}
+	var mismatch *gonedrive.HashMismatchError
+	if !errors.As(err, &mismatch) || mismatch.Algorithm != "quickXorHash" {
+		t.Errorf("got %#v", err)
+	}
+
+	// Nothing to check against with SkipVerify
+	if _, err := c.DownloadParallel(context.Background(), gonedrive.ItemByPath("file.bin"), local, gonedrive.ParallelDownloadParams{
+		ChunkSize:   7000,
+		Connections: 4,
+		SkipVerify:  true,
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,343 @@
+package gonedrivetest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Returned during replay, when no recorded interaction matches a request.
+var ErrNoInteraction = errors.New("no recorded interaction matches request")
+
+// Replaces secrets in recorded traffic.
+const redacted = "REDACTED"
+
+// Pre-authenticated URLs are replaced with URLs on this host.
+const redactedHost = "redacted.invalid"
+
+// Form values holding credentials.
+var secretFormFields = map[string]bool{
+	"refresh_token":    true,
+	"client_secret":    true,
+	"client_assertion": true,
+	"code":             true,
+	"code_verifier":    true,
+	"device_code":      true,
+}
+
+// JSON fields holding credentials.
+var secretJSONFields = map[string]bool{
+	"access_token":  true,
+	"refresh_token": true,
+	"id_token":      true,
+	"device_code":   true,
+}
+
+// JSON fields holding pre-authenticated URLs.
+var preauthFields = map[string]bool{
+	"@content.downloadUrl": true,
+	"uploadUrl":            true,
+}
+
+// Recorded HTTP traffic.
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// A single request and its response.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Method string       `json:"method"`
+	URL    string       `json:"url"`
+	Header http.Header  `json:"header,omitempty"`
+	Body   RecordedBody `json:"body"`
+}
+
+type RecordedResponse struct {
+	StatusCode int          `json:"status_code"`
+	Header     http.Header  `json:"header,omitempty"`
+	Body       RecordedBody `json:"body"`
+}
+
+// Body of a request or response.
+// Text bodies are stored as is, binary bodies are base64 encoded.
+type RecordedBody struct {
+	Text   string `json:"text,omitempty"`
+	Base64 string `json:"base64,omitempty"`
+}
+
+func newRecordedBody(data []byte) RecordedBody {
+	if utf8.Valid(data) {
+		return RecordedBody{Text: string(data)}
+	}
+	return RecordedBody{Base64: base64.StdEncoding.EncodeToString(data)}
+}
+
+func (body RecordedBody) bytes() []byte {
+	if body.Base64 != "" {
+		data, _ := base64.StdEncoding.DecodeString(body.Base64)
+		return data
+	}
+	return []byte(body.Text)
+}
+
+// An http.RoundTripper that records traffic to a cassette file,
+// or replays traffic from one.
+//
+// Recorded traffic is redacted: authorization headers, tokens and client
+// secrets are replaced, and pre-authenticated download and upload URLs are
+// replaced with placeholder URLs. Requests to those URLs are recorded with
+// the placeholder, so they still match during replay.
+//
+// During replay, requests are matched by method, path and query.
+// Matching interactions are used in the order they were recorded.
+type Recorder struct {
+	replay    bool
+	path      string
+	transport http.RoundTripper
+	mux       sync.Mutex
+	cassette  Cassette
+	used      []bool
+	preauth   map[string]string
+}
+
+// Creates a recorder that sends requests through transport,
+// and records them. Call Save to write the cassette file.
+// If transport is nil, http.DefaultTransport is used.
+func NewRecorder(path string, transport http.RoundTripper) *Recorder {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &Recorder{
+		path:      path,
+		transport: transport,
+		preauth:   map[string]string{},
+	}
+}
+
+// Creates a recorder that replays the given cassette file.
+// No requests leave the process.
+func NewReplayer(path string) (*Recorder, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	r := &Recorder{
+		replay: true,
+		path:   path,
+	}
+	if err := json.Unmarshal(data, &r.cassette); err != nil {
+		return nil, err
+	}
+	r.used = make([]bool, len(r.cassette.Interactions))
+	return r, nil
+}
+
+// Returns an HTTP client using the recorder as its transport.
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// Writes the recorded cassette to its file.
+func (r *Recorder) Save() error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	data, err := json.MarshalIndent(&r.cassette, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(r.path, data, 0o600)
+}
+
+func (r *Recorder) RoundTrip(request *http.Request) (*http.Response, error) {
+	if r.replay {
+		return r.replayRequest(request)
+	}
+	return r.recordRequest(request)
+}
+
+func (r *Recorder) replayRequest(request *http.Request) (*http.Response, error) {
+	if request.Body != nil {
+		request.Body.Close()
+	}
+
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	key := matchKey(request.Method, request.URL)
+	for i, interaction := range r.cassette.Interactions {
+		if r.used[i] {
+			continue
+		}
+		recordedURL, err := url.Parse(interaction.Request.URL)
+		if err != nil || matchKey(interaction.Request.Method, recordedURL) != key {
+			continue
+		}
+
+		// Found it
+		r.used[i] = true
+		body := interaction.Response.Body.bytes()
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        interaction.Response.Header.Clone(),
+			Body:          io.NopCloser(bytes.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       request,
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNoInteraction, key)
+}
+
+// Requests match if method, path and query are the same.
+// Query parameter order does not matter.
+func matchKey(method string, u *url.URL) string {
+	return method + " " + u.EscapedPath() + "?" + u.Query().Encode()
+}
+
+func (r *Recorder) recordRequest(request *http.Request) (*http.Response, error) {
+	// Keep a copy of the request body
+	var requestBody []byte
+	if request.Body != nil {
+		var err error
+		requestBody, err = io.ReadAll(request.Body)
+		request.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		request.Body = io.NopCloser(bytes.NewReader(requestBody))
+	}
+
+	response, err := r.transport.RoundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep a copy of the response body
+	responseBody, err := io.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	response.Body = io.NopCloser(bytes.NewReader(responseBody))
+
+	// Record redacted copy
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	interaction := &Interaction{
+		Request: RecordedRequest{
+			Method: request.Method,
+			URL:    r.redactURL(request.URL.String(), false),
+			Header: redactHeader(request.Header),
+			Body:   newRecordedBody(r.redactBody(request.Header, requestBody)),
+		},
+		Response: RecordedResponse{
+			StatusCode: response.StatusCode,
+			Header:     redactHeader(response.Header),
+			Body:       newRecordedBody(r.redactBody(response.Header, responseBody)),
+		},
+	}
+
+	// Redirect bodies usually repeat the URL, so they are dropped too
+	if location := interaction.Response.Header.Get("Location"); location != "" {
+		interaction.Response.Header.Set("Location", r.redactURL(location, true))
+		interaction.Response.Body = RecordedBody{}
+	}
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	return response, nil
+}
+
+// Replaces pre-authenticated URLs with placeholders.
+// If add is true, URLs not seen before are given a placeholder,
+// otherwise they are left as they are.
+func (r *Recorder) redactURL(raw string, add bool) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	u.RawQuery = ""
+	key := u.String()
+	if placeholder, ok := r.preauth[key]; ok {
+		return placeholder
+	}
+	if !add {
+		return raw
+	}
+	placeholder := fmt.Sprintf("https://%s/preauth/%d", redactedHost, len(r.preauth)+1)
+	r.preauth[key] = placeholder
+	return placeholder
+}
+
+func redactHeader(header http.Header) http.Header {
+	out := header.Clone()
+	if out.Get("Authorization") != "" {
+		out.Set("Authorization", redacted)
+	}
+	out.Del("Content-Length")
+	return out
+}
+
+// Redacts secrets from form and JSON bodies.
+func (r *Recorder) redactBody(header http.Header, body []byte) []byte {
+	contentType := header.Get("Content-Type")
+	switch {
+	case strings.HasPrefix(contentType, "application/x-www-form-urlencoded"):
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			return body
+		}
+		for key := range form {
+			if secretFormFields[key] {
+				form.Set(key, redacted)
+			}
+		}
+		return []byte(form.Encode())
+
+	case strings.HasPrefix(contentType, "application/json"):
+		var v any
+		if json.Unmarshal(body, &v) != nil {
+			return body
+		}
+		data, err := json.Marshal(r.redactJSON(v))
+		if err != nil {
+			return body
+		}
+		return data
+	}
+	return body
+}
+
+func (r *Recorder) redactJSON(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if str, ok := value.(string); ok && secretJSONFields[key] {
+				v[key] = redacted
+			} else if ok && preauthFields[key] {
+				v[key] = r.redactURL(str, true)
+			} else {
+				v[key] = r.redactJSON(value)
+			}
+		}
+	case []any:
+		for i, value := range v {
+			v[i] = r.redactJSON(value)
+		}
+	}
+	return v
+}
@@ -0,0 +1,94 @@
+package gonedrivetest_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sukus21/gonedrive"
+	"github.com/sukus21/gonedrive/gonedrivetest"
+)
+
+// Runs a token refresh, a listing, a download and an upload through httpClient.
+func recorderSession(t *testing.T, config gonedrive.TokenConfig, httpClient *http.Client) {
+	t.Helper()
+	ctx := context.Background()
+
+	// Expired token, so it is refreshed first
+	token := gonedrive.NewGraphToken(config)
+	token.ClientID = gonedrivetest.ClientID
+	token.AccessToken = "expired"
+	token.RefreshToken = "fake-refresh-token"
+	token.ExpiresAt = time.Now().Add(-time.Hour)
+	token.SetHTTPClient(httpClient)
+	c := gonedrive.NewClient(
+		gonedrive.WithTokenSource(token),
+		gonedrive.WithHTTPClient(httpClient),
+	)
+
+	items, err := c.ListFolder(ctx, gonedrive.ItemByPath("Music"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Name != "song.mp3" {
+		t.Fatalf("unexpected listing %v", items)
+	}
+
+	body, err := c.DownloadDriveItem(ctx, items[0].Ref())
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := io.ReadAll(body)
+	body.Close()
+	if err != nil || string(content) != "la la la" {
+		t.Fatalf("downloaded %q (%v)", content, err)
+	}
+
+	upload := []byte("new content")
+	item, err := c.UploadContent(ctx, bytes.NewReader(upload), int64(len(upload)), gonedrive.ItemByPath("Music/new.txt"), gonedrive.UploadSessionParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.Size != int64(len(upload)) {
+		t.Fatalf("uploaded item has size %d", item.Size)
+	}
+}
+
+func TestRecorderRedactsAndReplays(t *testing.T) {
+	s := gonedrivetest.NewServer()
+	s.AddFile("Music/song.mp3", []byte("la la la"))
+	config := gonedrive.TokenConfig{AuthorityHost: s.URL, GraphURL: s.URL}
+	cassette := filepath.Join(t.TempDir(), "cassette.json")
+
+	// Record
+	recorder := gonedrivetest.NewRecorder(cassette, nil)
+	recorderSession(t, config, recorder.Client())
+	if err := recorder.Save(); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	// No secrets in the cassette
+	data, err := os.ReadFile(cassette)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"fake-access-token", "fake-refresh-token", "tempauth", "Bearer "} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("cassette contains %q", secret)
+		}
+	}
+
+	// Replay, with the server gone
+	replayer, err := gonedrivetest.NewReplayer(cassette)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorderSession(t, config, replayer.Client())
+}
@@ -0,0 +1,838 @@
+// Package gonedrivetest provides an in-memory fake of the MS graph API,
+// for testing code that uses gonedrive without a Microsoft account.
+//
+// The fake implements the OAuth token endpoint and the drive endpoints used by
+// gonedrive, keeping the drive contents in memory. Faults can be injected to
+// test how code deals with throttling, server errors and broken connections.
+package gonedrivetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sukus21/gonedrive"
+)
+
+// ID of the drive of the signed-in user.
+const driveID = "fakedrive"
+
+// Size of every fake drive, in bytes.
+const driveQuota = 5 << 30
+
+// Client ID used by tokens from Server.Token.
+const ClientID = "gonedrivetest"
+
+// Default maximum number of children returned per page.
+const DefaultPageSize = 200
+
+// Fake Graph API and OAuth server.
+// A Server is safe for concurrent use.
+type Server struct {
+	// Base URL of the server.
+	// This is both the authority host and the Graph URL.
+	URL string
+
+	// Maximum number of children returned per page,
+	// unless the request asks for fewer using $top.
+	PageSize int
+
+	srv      *httptest.Server
+	mux      sync.Mutex
+	drive    *Drive
+	drives   []*Drive
+	items    map[string]*item
+	sessions map[string]*uploadSession
+	recycled map[string]*recycledItem
+	monitors map[string]*copyMonitor
+	tokens   map[string]bool
+	faults   []*Fault
+	requests []string
+	nextID   int
+}
+
+// Starts a new fake server, where the signed-in user has an empty drive.
+// The caller should call Close when done with it.
+func NewServer() *Server {
+	s := &Server{
+		PageSize: DefaultPageSize,
+		items:    map[string]*item{},
+		sessions: map[string]*uploadSession{},
+		recycled: map[string]*recycledItem{},
+		monitors: map[string]*copyMonitor{},
+		tokens:   map[string]bool{},
+	}
+	s.drive = s.addDrive("me", driveID, "OneDrive", "personal")
+	s.srv = httptest.NewServer(s)
+	s.URL = s.srv.URL
+	return s
+}
+
+// Adds an empty drive, owned by "me", "users/{id}", "groups/{id}" or "sites/{id}".
+// The drive is listed by the drives of its owner, and the first drive of an
+// owner is also its default drive.
+func (s *Server) AddDrive(owner string, id string, name string) *Drive {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	driveType := "business"
+	if strings.HasPrefix(owner, "sites/") || strings.HasPrefix(owner, "groups/") {
+		driveType = "documentLibrary"
+	}
+	return s.addDrive(owner, id, name, driveType)
+}
+
+func (s *Server) addDrive(owner string, id string, name string, driveType string) *Drive {
+	d := &Drive{
+		s:         s,
+		id:        id,
+		name:      name,
+		owner:     owner,
+		driveType: driveType,
+	}
+	now := time.Now()
+	d.root = &item{
+		id:       s.newID(),
+		name:     "root",
+		drive:    d,
+		folder:   true,
+		created:  now,
+		modified: now,
+	}
+	s.items[d.root.id] = d.root
+	s.drives = append(s.drives, d)
+	return d
+}
+
+// Drive of the signed-in user, which the Server helpers operate on.
+func (s *Server) MyDrive() *Drive {
+	return s.drive
+}
+
+// Shuts down the server.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// Returns a signed-in token for the fake server.
+// The token refreshes itself against the fake token endpoint.
+func (s *Server) Token() *gonedrive.GraphToken {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	t := gonedrive.NewGraphToken(gonedrive.TokenConfig{
+		AuthorityHost: s.URL,
+		GraphURL:      s.URL,
+	})
+	t.ClientID = ClientID
+	t.TokenType = "Bearer"
+	t.AccessToken = s.issueToken()
+	t.RefreshToken = "fake-refresh-token"
+	t.ExpiresIn = 3600
+	t.ExpiresAt = time.Now().Add(time.Hour)
+	t.SetHTTPClient(s.srv.Client())
+	return t
+}
+
+// Creates a client for the fake server, signed in with a token from Token.
+// Retries are made without delay, options can override this.
+func (s *Server) NewClient(opts ...gonedrive.ClientOption) *gonedrive.Client {
+	defaults := []gonedrive.ClientOption{
+		gonedrive.WithTokenSource(s.Token()),
+		gonedrive.WithHTTPClient(s.srv.Client()),
+		gonedrive.WithRetryPolicy(gonedrive.RetryPolicy{MaxAttempts: 5}),
+	}
+	return gonedrive.NewClient(append(defaults, opts...)...)
+}
+
+// Invalidates all access tokens issued so far.
+// Requests using them fail with InvalidAuthenticationToken, until they are refreshed.
+func (s *Server) ExpireTokens() {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	clear(s.tokens)
+}
+
+// Returns "METHOD /path" for every request received so far.
+func (s *Server) Requests() []string {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return append([]string{}, s.requests...)
+}
+
+func (s *Server) newID() string {
+	s.nextID++
+	return fmt.Sprintf("FAKE!%d", s.nextID)
+}
+
+func (s *Server) issueToken() string {
+	token := fmt.Sprintf("fake-access-token-%d", s.nextID)
+	s.nextID++
+	s.tokens[token] = true
+	return token
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.Lock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.EscapedPath())
+	fault := s.takeFault(r)
+	s.mux.Unlock()
+
+	if fault != nil {
+		s.serveFault(fault, w, r)
+		return
+	}
+	s.route(w, r)
+}
+
+func (s *Server) route(w http.ResponseWriter, r *http.Request) {
+	p := r.URL.EscapedPath()
+	switch {
+	case strings.HasSuffix(p, "/oauth2/v2.0/token"):
+		s.handleToken(w, r)
+	case strings.HasSuffix(p, "/oauth2/v2.0/devicecode"):
+		s.handleDeviceCode(w, r)
+	case strings.HasPrefix(p, "/download/"):
+		s.handleDownload(w, r, strings.TrimPrefix(p, "/download/"))
+	case strings.HasPrefix(p, "/upload/"):
+		s.handleUpload(w, r, strings.TrimPrefix(p, "/upload/"))
+	case strings.HasPrefix(p, "/monitor/"):
+		s.handleMonitor(w, r, strings.TrimPrefix(p, "/monitor/"))
+	case strings.HasPrefix(p, "/"+gonedrive.APIVersionV1+"/") || strings.HasPrefix(p, "/"+gonedrive.APIVersionBeta+"/"):
+		if !s.authorized(r) {
+			writeError(w, http.StatusUnauthorized, "InvalidAuthenticationToken", "Access token is empty or invalid.")
+			return
+		}
+		_, rest, _ := strings.Cut(strings.TrimPrefix(p, "/"), "/")
+		s.handleGraph(w, r, rest)
+	default:
+		writeError(w, http.StatusNotFound, "invalidRequest", "Unknown endpoint.")
+	}
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	return ok && s.tokens[token]
+}
+
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	grant := r.PostForm.Get("grant_type")
+	switch grant {
+	case "refresh_token", "authorization_code", "client_credentials", "urn:ietf:params:oauth:grant-type:device_code":
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]any{
+			"error":             "unsupported_grant_type",
+			"error_description": "Unsupported grant type: " + grant,
+		})
+		return
+	}
+	if grant == "refresh_token" && r.PostForm.Get("refresh_token") == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]any{
+			"error":             "invalid_grant",
+			"error_description": "Refresh token is missing.",
+		})
+		return
+	}
+
+	s.mux.Lock()
+	response := map[string]any{
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"scope":        r.PostForm.Get("scope"),
+		"access_token": s.issueToken(),
+	}
+	if grant != "client_credentials" {
+		response["refresh_token"] = "fake-refresh-token"
+	}
+	s.mux.Unlock()
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) handleDeviceCode(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"user_code":        "FAKECODE",
+		"device_code":      "fake-device-code",
+		"verification_uri": s.URL + "/devicelogin",
+		"expires_in":       900,
+		"interval":         1,
+		"message":          "To sign in, enter the code FAKECODE at " + s.URL + "/devicelogin",
+	})
+}
+
+// Item address, relative to a drive.
+type address struct {
+	// "root", or the ID of an item.
+	base string
+
+	// Name of a special folder, used instead of base if set.
+	special string
+
+	// Path segments below base.
+	path []string
+
+	// What to do with the item, such as "children" or "content".
+	action string
+}
+
+// Drive part of a request path.
+type driveAddress struct {
+	// Owner of the drive, such as "me" or "sites/{id}".
+	// Empty if the drive is addressed by ID.
+	owner string
+
+	// ID of the drive, if addressed by ID.
+	id string
+
+	// The request lists the drives of owner.
+	list bool
+
+	// Escaped item address below the drive.
+	rest string
+}
+
+// Parses the drive part of an escaped request path, such as "me/drive/root",
+// "drives/{id}/items/{id}" or "sites/{id}/drives".
+func parseDriveAddress(escaped string) (*driveAddress, bool) {
+	addr := &driveAddress{}
+	segs := strings.Split(escaped, "/")
+	switch {
+	case segs[0] == "me":
+		addr.owner = "me"
+		segs = segs[1:]
+	case len(segs) >= 2 && (segs[0] == "drives" || segs[0] == "users" || segs[0] == "groups" || segs[0] == "sites"):
+		id, err := url.PathUnescape(segs[1])
+		if err != nil {
+			return nil, false
+		}
+		if segs[0] == "drives" {
+			addr.id = id
+			addr.rest = strings.Join(segs[2:], "/")
+			return addr, true
+		}
+		addr.owner = segs[0] + "/" + id
+		segs = segs[2:]
+	default:
+		return nil, false
+	}
+
+	// Default drive, or list of drives?
+	switch {
+	case len(segs) == 1 && segs[0] == "drives":
+		addr.list = true
+	case len(segs) >= 1 && segs[0] == "drive":
+		addr.rest = strings.Join(segs[1:], "/")
+	default:
+		return nil, false
+	}
+	return addr, true
+}
+
+// Finds the drive at addr, or nil if there is none.
+func (s *Server) findDrive(addr *driveAddress) *Drive {
+	for _, d := range s.drives {
+		if (addr.id != "" && d.id == addr.id) || (addr.owner != "" && d.owner == addr.owner) {
+			return d
+		}
+	}
+	return nil
+}
+
+func (s *Server) handleListDrives(w http.ResponseWriter, r *http.Request, owner string) {
+	value := []any{}
+	for _, d := range s.drives {
+		if d.owner == owner {
+			value = append(value, selectFields(r, d.json()))
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"@odata.context": s.URL + "/v1.0/$metadata#drives",
+		"value":          value,
+	})
+}
+
+// Parses an escaped item address, such as "root:/a/b:/children" or "items/{id}".
+func parseAddress(escaped string) (*address, bool) {
+	addr := &address{}
+	rest := ""
+	if after, ok := strings.CutPrefix(escaped, "root"); ok {
+		addr.base = "root"
+		rest = after
+	} else if after, ok := strings.CutPrefix(escaped, "items/"); ok {
+		addr.base, rest, ok = cutSegment(after)
+		if !ok {
+			return nil, false
+		}
+	} else if after, ok := strings.CutPrefix(escaped, "special/"); ok {
+		addr.special, rest, ok = cutSegment(after)
+		if !ok {
+			return nil, false
+		}
+	} else {
+		return nil, false
+	}
+
+	// Path relative to base
+	if after, ok := strings.CutPrefix(rest, ":"); ok {
+		p, after, _ := strings.Cut(after, ":")
+		for _, seg := range strings.Split(p, "/") {
+			if seg == "" {
+				continue
+			}
+			seg, err := url.PathUnescape(seg)
+			if err != nil {
+				return nil, false
+			}
+			addr.path = append(addr.path, seg)
+		}
+		rest = after
+	}
+
+	addr.action = strings.Trim(rest, "/")
+	return addr, true
+}
+
+// Cuts an escaped ID from the start of an address, returning it unescaped.
+func cutSegment(escaped string) (segment string, rest string, ok bool) {
+	end := strings.IndexAny(escaped, "/:")
+	if end == -1 {
+		end = len(escaped)
+	}
+	segment, err := url.PathUnescape(escaped[:end])
+	return segment, escaped[end:], err == nil
+}
+
+// Folders in the drive root used as special folders.
+// Like OneDrive, they are created when first used.
+var specialFolders = map[string]string{
+	"documents":  "Documents",
+	"photos":     "Pictures",
+	"cameraroll": "Camera Roll",
+	"approot":    "Apps",
+	"music":      "Music",
+	"recordings": "Recordings",
+}
+
+// Finds the item at addr.
+// If it does not exist, the closest existing folder is returned along with
+// the path segments missing below it.
+func (d *Drive) resolve(addr *address) (found *item, missing []string) {
+	found = d.root
+	if addr.special != "" {
+		name, ok := specialFolders[addr.special]
+		if !ok {
+			return nil, nil
+		}
+		found = d.root.child(name)
+		if found == nil {
+			found = d.s.mkdirAll(d.root, []string{name})
+		}
+	} else if addr.base != "root" {
+		found = d.s.items[addr.base]
+		if found == nil || found.drive != d {
+			return nil, nil
+		}
+	}
+	for i, seg := range addr.path {
+		child := found.child(seg)
+		if child == nil {
+			return found, addr.path[i:]
+		}
+		found = child
+	}
+	return found, nil
+}
+
+func (s *Server) handleGraph(w http.ResponseWriter, r *http.Request, p string) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	// Which drive?
+	driveAddr, ok := parseDriveAddress(p)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "invalidRequest", "Invalid request.")
+		return
+	}
+	if driveAddr.list {
+		s.handleListDrives(w, r, driveAddr.owner)
+		return
+	}
+	d := s.findDrive(driveAddr)
+	if d == nil {
+		writeError(w, http.StatusNotFound, "itemNotFound", "The drive could not be found.")
+		return
+	}
+	if driveAddr.rest == "" && r.Method == "GET" {
+		writeJSON(w, http.StatusOK, selectFields(r, d.json()))
+		return
+	}
+
+	addr, ok := parseAddress(driveAddr.rest)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "invalidRequest", "Invalid request.")
+		return
+	}
+	if r.Method == "POST" && addr.action == "restore" {
+		s.handleRestore(w, r, d, addr)
+		return
+	}
+	it, missing := d.resolve(addr)
+	if it == nil || (len(missing) != 0 && !(r.Method == "POST" && addr.action == "createUploadSession")) {
+		writeError(w, http.StatusNotFound, "itemNotFound", "The resource could not be found.")
+		return
+	}
+
+	switch {
+	case r.Method == "GET" && addr.action == "":
+		writeJSON(w, http.StatusOK, selectFields(r, it.json(s)))
+	case r.Method == "GET" && addr.action == "children":
+		s.handleChildren(w, r, it)
+	case r.Method == "GET" && strings.HasPrefix(addr.action, "search("):
+		s.handleSearch(w, r, it, addr.action)
+	case r.Method == "GET" && addr.action == "content":
+		if it.folder {
+			writeError(w, http.StatusNotFound, "itemNotFound", "Folders have no content.")
+			return
+		}
+		http.Redirect(w, r, s.downloadURL(it), http.StatusFound)
+	case r.Method == "PATCH" && addr.action == "":
+		s.handleUpdate(w, r, it)
+	case r.Method == "DELETE" && addr.action == "":
+		s.handleDelete(w, r, it, false)
+	case r.Method == "POST" && addr.action == "permanentDelete":
+		s.handleDelete(w, r, it, true)
+	case r.Method == "POST" && addr.action == "copy":
+		s.handleCopy(w, r, it)
+	case r.Method == "POST" && addr.action == "children":
+		s.handleCreateFolder(w, r, it)
+	case r.Method == "POST" && addr.action == "createUploadSession":
+		s.handleCreateUploadSession(w, r, it, missing)
+	default:
+		writeError(w, http.StatusBadRequest, "invalidRequest", "Unsupported request.")
+	}
+}
+
+func (s *Server) handleChildren(w http.ResponseWriter, r *http.Request, it *item) {
+	if !it.folder {
+		writeError(w, http.StatusBadRequest, "invalidRequest", "Item is not a folder.")
+		return
+	}
+	s.writePage(w, r, it.children)
+}
+
+func (s *Server) handleCreateFolder(w http.ResponseWriter, r *http.Request, parent *item) {
+	var body struct {
+		Name             string    `json:"name"`
+		Folder           *struct{} `json:"folder"`
+		ConflictBehavior string    `json:"@microsoft.graph.conflictBehavior"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Name == "" || body.Folder == nil {
+		writeError(w, http.StatusBadRequest, "invalidRequest", "Only folders can be created.")
+		return
+	}
+	if !parent.folder {
+		writeError(w, http.StatusBadRequest, "invalidRequest", "Item is not a folder.")
+		return
+	}
+
+	// Name taken?
+	name := body.Name
+	if existing := parent.child(name); existing != nil {
+		switch body.ConflictBehavior {
+		case "rename":
+			name = uniqueName(parent, name)
+		case "replace":
+			s.removeItem(existing)
+		default:
+			writeError(w, http.StatusConflict, "nameAlreadyExists", "An item with the same name already exists.")
+			return
+		}
+	}
+	parent.touch(time.Now())
+	writeJSON(w, http.StatusCreated, s.mkdirAll(parent, []string{name}).json(s))
+}
+
+// Finds items below it whose name contains the search text.
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request, it *item, action string) {
+	text, ok := strings.CutPrefix(action, "search(q='")
+	text, ok2 := strings.CutSuffix(text, "')")
+	text, err := url.PathUnescape(text)
+	if !ok || !ok2 || err != nil {
+		writeError(w, http.StatusBadRequest, "invalidRequest", "Invalid search query.")
+		return
+	}
+	text = strings.ToLower(strings.ReplaceAll(text, "''", "'"))
+
+	found := []*item{}
+	var walk func(it *item)
+	walk = func(it *item) {
+		for _, child := range it.children {
+			if strings.Contains(strings.ToLower(child.name), text) {
+				found = append(found, child)
+			}
+			walk(child)
+		}
+	}
+	walk(it)
+	s.writePage(w, r, found)
+}
+
+// Writes one page of a collection, with a nextLink if there are more.
+func (s *Server) writePage(w http.ResponseWriter, r *http.Request, items []*item) {
+	// Which page?
+	query := r.URL.Query()
+	pageSize := s.PageSize
+	if top, err := strconv.Atoi(queryOption(query, "$top")); err == nil && top > 0 {
+		pageSize = min(pageSize, top)
+	}
+	start, _ := strconv.Atoi(queryOption(query, "$skipToken"))
+	start = min(max(start, 0), len(items))
+	end := min(start+pageSize, len(items))
+
+	// Build page
+	value := []any{}
+	for _, child := range items[start:end] {
+		value = append(value, selectFields(r, child.json(s)))
+	}
+	response := map[string]any{
+		"@odata.context": s.URL + "/v1.0/$metadata#Collection(driveItem)",
+		"value":          value,
+	}
+	if end < len(items) {
+		for key := range query {
+			if strings.EqualFold(key, "$skipToken") {
+				query.Del(key)
+			}
+		}
+		query.Set("$skiptoken", strconv.Itoa(end))
+		response["@odata.nextLink"] = s.URL + r.URL.EscapedPath() + "?" + query.Encode()
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// Pre-authenticated download URL for a file.
+func (s *Server) downloadURL(it *item) string {
+	return fmt.Sprintf("%s/download/%s?tempauth=%s", s.URL, url.PathEscape(it.id), it.etag())
+}
+
+func (s *Server) handleDownload(w http.ResponseWriter, r *http.Request, escapedID string) {
+	id, _ := url.PathUnescape(escapedID)
+	s.mux.Lock()
+	it := s.items[id]
+	if it == nil || it.folder {
+		s.mux.Unlock()
+		writeError(w, http.StatusNotFound, "itemNotFound", "The resource could not be found.")
+		return
+	}
+	content := strings.NewReader(string(it.content))
+	modified := it.modified
+	name := it.name
+	s.mux.Unlock()
+
+	// Handles range requests too
+	http.ServeContent(w, r, name, modified, content)
+}
+
+// Creates the missing folders below parent.
+func (s *Server) mkdirAll(parent *item, missing []string) *item {
+	now := time.Now()
+	for _, name := range missing {
+		folder := &item{
+			id:       s.newID(),
+			name:     name,
+			folder:   true,
+			created:  now,
+			modified: now,
+		}
+		s.items[folder.id] = folder
+		parent.addChild(folder)
+		parent = folder
+	}
+	return parent
+}
+
+// Adds a file with the given content, creating missing folders along the way.
+// An existing file at the path is replaced.
+func (d *Drive) AddFile(p string, content []byte) *gonedrive.DriveItem {
+	d.s.mux.Lock()
+	defer d.s.mux.Unlock()
+
+	dir, name := path.Split(strings.Trim(p, "/"))
+	parent, missing := d.resolve(&address{base: "root", path: splitPath(dir)})
+	parent = d.s.mkdirAll(parent, missing)
+	return toDriveItem(d.s, d.s.writeFile(parent, name, content, time.Now()))
+}
+
+// Adds a folder, creating missing parent folders along the way.
+func (d *Drive) AddFolder(p string) *gonedrive.DriveItem {
+	d.s.mux.Lock()
+	defer d.s.mux.Unlock()
+
+	it, missing := d.resolve(&address{base: "root", path: splitPath(p)})
+	return toDriveItem(d.s, d.s.mkdirAll(it, missing))
+}
+
+// Returns the item at the given path, or nil if it does not exist.
+func (d *Drive) Item(p string) *gonedrive.DriveItem {
+	d.s.mux.Lock()
+	defer d.s.mux.Unlock()
+
+	it, missing := d.resolve(&address{base: "root", path: splitPath(p)})
+	if len(missing) != 0 {
+		return nil
+	}
+	return toDriveItem(d.s, it)
+}
+
+// Returns the content of the file at the given path.
+func (d *Drive) ReadFile(p string) ([]byte, bool) {
+	d.s.mux.Lock()
+	defer d.s.mux.Unlock()
+
+	it, missing := d.resolve(&address{base: "root", path: splitPath(p)})
+	if len(missing) != 0 || it.folder {
+		return nil, false
+	}
+	return append([]byte{}, it.content...), true
+}
+
+// Removes the file or folder at the given path.
+// Reports whether anything was removed.
+func (d *Drive) Remove(p string) bool {
+	d.s.mux.Lock()
+	defer d.s.mux.Unlock()
+
+	it, missing := d.resolve(&address{base: "root", path: splitPath(p)})
+	if len(missing) != 0 || it.isRoot() {
+		return false
+	}
+	d.s.removeItem(it)
+	return true
+}
+
+// Same as Drive.AddFile, on the drive of the signed-in user.
+func (s *Server) AddFile(p string, content []byte) *gonedrive.DriveItem {
+	return s.drive.AddFile(p, content)
+}
+
+// Same as Drive.AddFolder, on the drive of the signed-in user.
+func (s *Server) AddFolder(p string) *gonedrive.DriveItem {
+	return s.drive.AddFolder(p)
+}
+
+// Same as Drive.Item, on the drive of the signed-in user.
+func (s *Server) Item(p string) *gonedrive.DriveItem {
+	return s.drive.Item(p)
+}
+
+// Same as Drive.ReadFile, on the drive of the signed-in user.
+func (s *Server) ReadFile(p string) ([]byte, bool) {
+	return s.drive.ReadFile(p)
+}
+
+// Same as Drive.Remove, on the drive of the signed-in user.
+func (s *Server) Remove(p string) bool {
+	return s.drive.Remove(p)
+}
+
+// Removes an item and everything below it.
+func (s *Server) removeItem(it *item) {
+	for _, child := range slices.Clone(it.children) {
+		s.removeItem(child)
+	}
+	delete(s.items, it.id)
+	if it.parent != nil {
+		it.parent.touch(time.Now())
+		it.parent.removeChild(it)
+	}
+}
+
+// Writes a file into parent, replacing any existing file with the same name.
+func (s *Server) writeFile(parent *item, name string, content []byte, modified time.Time) *item {
+	it := parent.child(name)
+	if it == nil {
+		it = &item{
+			id:      s.newID(),
+			name:    name,
+			created: modified,
+		}
+		s.items[it.id] = it
+		parent.addChild(it)
+	}
+	it.content = append([]byte{}, content...)
+	it.touch(modified)
+	return it
+}
+
+func splitPath(p string) []string {
+	segments := []string{}
+	for _, seg := range strings.Split(p, "/") {
+		if seg != "" {
+			segments = append(segments, seg)
+		}
+	}
+	return segments
+}
+
+func toDriveItem(s *Server, it *item) *gonedrive.DriveItem {
+	data, _ := json.Marshal(it.json(s))
+	out := &gonedrive.DriveItem{}
+	json.Unmarshal(data, out)
+	return out
+}
+
+// Returns a query option, ignoring case like the API does.
+func queryOption(query url.Values, name string) string {
+	for key, values := range query {
+		if strings.EqualFold(key, name) && len(values) != 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+// Applies $select from the request to a serialized item.
+func selectFields(r *http.Request, out map[string]any) map[string]any {
+	selected := queryOption(r.URL.Query(), "$select")
+	if selected == "" {
+		return out
+	}
+	filtered := map[string]any{}
+	for _, field := range strings.Split(selected, ",") {
+		if value, ok := out[strings.TrimSpace(field)]; ok {
+			filtered[field] = value
+		}
+	}
+	return filtered
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	data, _ := json.Marshal(v)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.WriteHeader(status)
+	w.Write(data)
+}
+
+func writeError(w http.ResponseWriter, status int, code string, message string) {
+	writeJSON(w, status, map[string]any{
+		"error": map[string]any{
+			"code":    code,
+			"message": message,
+			"innerError": map[string]any{
+				"date":       time.Now().UTC().Format(time.RFC3339),
+				"request-id": "00000000-0000-0000-0000-000000000000",
+			},
+		},
+	})
+}
@@ -0,0 +1,237 @@
+package gonedrivetest_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sukus21/gonedrive"
+	"github.com/sukus21/gonedrive/gonedrivetest"
+)
+
+// Counts the requests whose "METHOD /path" contains all of parts.
+func countRequests(s *gonedrivetest.Server, parts ...string) int {
+	n := 0
+	for _, request := range s.Requests() {
+		matches := true
+		for _, part := range parts {
+			matches = matches && strings.Contains(request, part)
+		}
+		if matches {
+			n++
+		}
+	}
+	return n
+}
+
+func TestListFolderPaging(t *testing.T) {
+	s := gonedrivetest.NewServer()
+	defer s.Close()
+	s.PageSize = 3
+	for i := 0; i < 10; i++ {
+		s.AddFile(fmt.Sprintf("Music/song %02d.mp3", i), []byte{byte(i)})
+	}
+
+	items, err := s.NewClient().ListFolder(context.Background(), gonedrive.ItemByPath("Music"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 10 {
+		t.Fatalf("got %d items, want 10", len(items))
+	}
+	for i, item := range items {
+		if want := fmt.Sprintf("song %02d.mp3", i); item.Name != want {
+			t.Errorf("item %d is %q, want %q", i, item.Name, want)
+		}
+	}
+	if n := countRequests(s, "GET", "/children"); n != 4 {
+		t.Errorf("made %d page requests, want 4", n)
+	}
+}
+
+func TestUploadContentChunks(t *testing.T) {
+	s := gonedrivetest.NewServer()
+	defer s.Close()
+	content := bytes.Repeat([]byte("0123456789"), 250000)
+
+	item, err := s.NewClient().UploadContent(
+		context.Background(),
+		bytes.NewReader(content),
+		int64(len(content)),
+		gonedrive.ItemByPath("Backups/data.bin"),
+		gonedrive.UploadSessionParams{ConflictBehaviour: gonedrive.ConflictBehaviour_Fail},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.Size != int64(len(content)) {
+		t.Errorf("uploaded item has size %d, want %d", item.Size, len(content))
+	}
+	stored, ok := s.ReadFile("Backups/data.bin")
+	if !ok || !bytes.Equal(stored, content) {
+		t.Error("stored content does not match uploaded content")
+	}
+	if n := countRequests(s, "PUT", "/upload/"); n != 3 {
+		t.Errorf("sent %d chunks, want 3", n)
+	}
+}
+
+func TestSyncFolderFaults(t *testing.T) {
+	s := gonedrivetest.NewServer()
+	defer s.Close()
+	files := map[string]string{
+		"a.txt": "first file",
+		"b.txt": "second file",
+		"c.txt": "third file",
+	}
+	for name, content := range files {
+		s.AddFile("Sync/"+name, []byte(content))
+	}
+	c := s.NewClient()
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	// Throttled listing is retried, truncated download is reported
+	s.InjectFault(gonedrivetest.Fault{Path: "/children", StatusCode: 429, RetryAfter: 0, Times: 1})
+	s.InjectFault(gonedrivetest.Fault{Path: "/content", Truncate: true, Times: 1})
+	errorCount := 0
+	err := c.SyncFolder(ctx, gonedrive.ItemByPath("Sync"), dir, nil, func(event gonedrive.SyncEvent) {
+		if _, ok := event.(*gonedrive.SyncEventError); ok {
+			errorCount++
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errorCount != 1 {
+		t.Errorf("got %d error events, want 1", errorCount)
+	}
+	if n := countRequests(s, "GET", "/children"); n != 2 {
+		t.Errorf("made %d listing requests, want 2", n)
+	}
+
+	// Second sync picks up the file that failed
+	if err := c.SyncFolder(ctx, gonedrive.ItemByPath("Sync"), dir, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		local, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil || string(local) != content {
+			t.Errorf("%s has content %q (%v), want %q", name, local, err, content)
+		}
+	}
+}
+
+func TestExpireTokensRefresh(t *testing.T) {
+	s := gonedrivetest.NewServer()
+	defer s.Close()
+	s.AddFile("a.txt", []byte("a"))
+	c := s.NewClient()
+	ctx := context.Background()
+
+	if _, err := c.GetDriveItem(ctx, gonedrive.ItemByPath("a.txt"), nil); err != nil {
+		t.Fatal(err)
+	}
+	s.ExpireTokens()
+	if _, err := c.GetDriveItem(ctx, gonedrive.ItemByPath("a.txt"), nil); err != nil {
+		t.Fatal(err)
+	}
+	if n := countRequests(s, "POST", "/oauth2/v2.0/token"); n != 1 {
+		t.Errorf("refreshed %d times, want 1", n)
+	}
+	if n := countRequests(s, "GET", "a.txt"); n != 3 {
+		t.Errorf("sent %d item requests, want 3", n)
+	}
+}
+
+// Token source that starts failing after a number of tokens.
+type failingTokens struct {
+	gonedrive.TokenSource
+	remaining int
+}
+
+func (tokens *failingTokens) Token(ctx context.Context) (string, error) {
+	if tokens.remaining <= 0 {
+		return "", errors.New("token source failed")
+	}
+	tokens.remaining--
+	return tokens.TokenSource.Token(ctx)
+}
+
+func TestListFolderTokenFailure(t *testing.T) {
+	s := gonedrivetest.NewServer()
+	defer s.Close()
+	s.PageSize = 2
+	for i := 0; i < 5; i++ {
+		s.AddFile(fmt.Sprintf("Music/song %d.mp3", i), []byte{byte(i)})
+	}
+
+	// Fails when requesting the second page
+	c := s.NewClient(
+		gonedrive.WithTokenSource(&failingTokens{TokenSource: s.Token(), remaining: 1}),
+		gonedrive.WithBaseURL(s.URL+"/"+gonedrive.APIVersionV1),
+	)
+	items, err := c.ListFolder(context.Background(), gonedrive.ItemByPath("Music"), nil)
+	if err == nil {
+		t.Fatalf("got %d items, want error", len(items))
+	}
+	if n := countRequests(s, "GET", "/children"); n != 1 {
+		t.Errorf("made %d page requests, want 1", n)
+	}
+}
+
+// Transport that records the User-Agent of every request sent through it.
+type userAgentTransport struct {
+	mux        sync.Mutex
+	userAgents map[string]string
+}
+
+func (transport *userAgentTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	transport.mux.Lock()
+	transport.userAgents[request.URL.Path] = request.Header.Get("User-Agent")
+	transport.mux.Unlock()
+	return http.DefaultTransport.RoundTrip(request)
+}
+
+func TestTokenUsesClientTransport(t *testing.T) {
+	s := gonedrivetest.NewServer()
+	defer s.Close()
+	s.AddFile("a.txt", []byte("a"))
+
+	// Expired token without an HTTP client
+	token := gonedrive.NewGraphToken(gonedrive.TokenConfig{AuthorityHost: s.URL, GraphURL: s.URL})
+	token.ClientID = gonedrivetest.ClientID
+	token.RefreshToken = "fake-refresh-token"
+	token.ExpiresAt = time.Now().Add(-time.Hour)
+
+	transport := &userAgentTransport{userAgents: map[string]string{}}
+	c := gonedrive.NewClient(
+		gonedrive.WithTokenSource(token),
+		gonedrive.WithHTTPClient(&http.Client{Transport: transport}),
+		gonedrive.WithUserAgent("gonedrive-test"),
+	)
+	if _, err := c.GetDriveItem(context.Background(), gonedrive.ItemByPath("a.txt"), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	found := false
+	for path, userAgent := range transport.userAgents {
+		if strings.HasSuffix(path, "/oauth2/v2.0/token") {
+			found = true
+		}
+		if userAgent != "gonedrive-test" {
+			t.Errorf("request to %s has User-Agent %q", path, userAgent)
+		}
+	}
+	if !found {
+		t.Errorf("token was not refreshed through the client transport, saw %v", transport.userAgents)
+	}
+}
@@ -0,0 +1,89 @@
+package gonedrivetest
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+func (s *Server) handleUpdate(w http.ResponseWriter, r *http.Request, it *item) {
+	var body struct {
+		Name            *string `json:"name"`
+		Description     *string `json:"description"`
+		ParentReference *struct {
+			ID string `json:"id"`
+		} `json:"parentReference"`
+		FileSystemInfo *struct {
+			CreatedDateTime      *time.Time `json:"createdDateTime"`
+			LastModifiedDateTime *time.Time `json:"lastModifiedDateTime"`
+		} `json:"fileSystemInfo"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalidRequest", "Invalid request body.")
+		return
+	}
+	if !ifMatch(r, it) {
+		writeError(w, http.StatusPreconditionFailed, "preconditionFailed", "The resource has changed since the caller last read it.")
+		return
+	}
+
+	// Where to?
+	parent := it.parent
+	name := it.name
+	if body.ParentReference != nil && body.ParentReference.ID != "" {
+		parent = s.items[body.ParentReference.ID]
+		if parent == nil || parent.drive != it.drive || !parent.folder {
+			writeError(w, http.StatusNotFound, "itemNotFound", "The parent folder could not be found.")
+			return
+		}
+	}
+	if body.Name != nil {
+		name = *body.Name
+	}
+	moved := parent != it.parent || name != it.name
+	if moved && it.isRoot() {
+		writeError(w, http.StatusForbidden, "accessDenied", "The root cannot be moved or renamed.")
+		return
+	}
+	if moved && parent.isBelow(it) {
+		writeError(w, http.StatusBadRequest, "invalidRequest", "An item cannot be moved into itself.")
+		return
+	}
+
+	// Name taken?
+	if existing := parent.child(name); moved && existing != nil && existing != it {
+		switch r.URL.Query().Get("@microsoft.graph.conflictBehavior") {
+		case "rename":
+			name = uniqueName(parent, name)
+		case "replace":
+			s.removeItem(existing)
+		default:
+			writeError(w, http.StatusConflict, "nameAlreadyExists", "An item with the same name already exists.")
+			return
+		}
+	}
+
+	// Apply changes
+	now := time.Now()
+	if moved {
+		oldParent := it.parent
+		oldParent.removeChild(it)
+		oldParent.touch(now)
+		it.name = name
+		parent.addChild(it)
+		parent.touch(now)
+	}
+	if body.Description != nil {
+		it.description = *body.Description
+	}
+	if info := body.FileSystemInfo; info != nil {
+		if info.CreatedDateTime != nil {
+			it.created = *info.CreatedDateTime
+		}
+		if info.LastModifiedDateTime != nil {
+			it.modified = *info.LastModifiedDateTime
+		}
+	}
+	it.version++
+	writeJSON(w, http.StatusOK, it.json(s))
+}
@@ -0,0 +1,84 @@
+package gonedrivetest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sukus21/gonedrive"
+	"github.com/sukus21/gonedrive/gonedrivetest"
+)
+
+func TestRenameConflicts(t *testing.T) {
+	s := gonedrivetest.NewServer()
+	defer s.Close()
+	s.AddFile("f.txt", []byte("one"))
+	second := s.AddFile("g.txt", []byte("two"))
+	third := s.AddFile("h.txt", []byte("three"))
+	c := s.NewClient()
+	ctx := context.Background()
+
+	_, err := c.RenameItem(ctx, gonedrive.ItemByID(second.Id), "f.txt", gonedrive.ConflictBehaviour_Fail)
+	if !errors.Is(err, gonedrive.ErrNameAlreadyExists) {
+		t.Fatalf("got %v, want ErrNameAlreadyExists", err)
+	}
+
+	renamed, err := c.RenameItem(ctx, gonedrive.ItemByID(second.Id), "f.txt", gonedrive.ConflictBehaviour_Rename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if renamed.Name != "f 1.txt" {
+		t.Errorf("renamed to %q, want %q", renamed.Name, "f 1.txt")
+	}
+
+	if _, err := c.RenameItem(ctx, gonedrive.ItemByID(third.Id), "f.txt", gonedrive.ConflictBehaviour_Replace); err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := s.ReadFile("f.txt"); string(content) != "three" {
+		t.Errorf("f.txt has content %q, want %q", content, "three")
+	}
+}
+
+func TestUpdateItem(t *testing.T) {
+	s := gonedrivetest.NewServer()
+	defer s.Close()
+	item := s.AddFile("docs/a.txt", []byte("a"))
+	s.AddFolder("archive")
+	c := s.NewClient()
+	ctx := context.Background()
+	ref := gonedrive.ItemByID(item.Id)
+	description := "first draft"
+	modified := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	// Stale eTag
+	_, err := c.UpdateItem(ctx, ref, gonedrive.UpdateItemParams{Description: &description, IfMatch: `"stale"`})
+	if !errors.Is(err, gonedrive.ErrPreconditionFailed) {
+		t.Fatalf("got %v, want ErrPreconditionFailed", err)
+	}
+
+	// Current eTag
+	updated, err := c.UpdateItem(ctx, ref, gonedrive.UpdateItemParams{
+		Description: &description,
+		ModifiedAt:  &modified,
+		IfMatch:     item.Etag,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Description != description || updated.Etag == item.Etag {
+		t.Errorf("updated %+v", updated)
+	}
+	if got, err := time.Parse(time.RFC3339, updated.ModifiedDate); err != nil || !got.Equal(modified) {
+		t.Errorf("modified at %q, want %s", updated.ModifiedDate, modified)
+	}
+
+	// Move by path, and rename on the way
+	moved, err := c.MoveItem(ctx, ref, gonedrive.ItemByPath("archive"), "b.txt", gonedrive.ConflictBehaviour_Fail)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moved.Id != item.Id || s.Item("docs/a.txt") != nil || s.Item("archive/b.txt") == nil {
+		t.Errorf("moved %+v", moved)
+	}
+}
@@ -0,0 +1,152 @@
+package gonedrivetest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// An upload session in progress.
+type uploadSession struct {
+	parent   *item
+	missing  []string
+	name     string
+	conflict string
+	modified time.Time
+	data     []byte
+	expires  time.Time
+}
+
+func (session *uploadSession) nextExpectedRanges() []string {
+	return []string{fmt.Sprintf("%d-", len(session.data))}
+}
+
+func (s *Server) handleCreateUploadSession(w http.ResponseWriter, r *http.Request, it *item, missing []string) {
+	var body struct {
+		Item struct {
+			ConflictBehavior string `json:"@microsoft.graph.conflictBehavior"`
+			FileSystemInfo   struct {
+				LastModifiedDateTime time.Time `json:"lastModifiedDateTime"`
+			} `json:"fileSystemInfo"`
+		} `json:"item"`
+	}
+	json.NewDecoder(r.Body).Decode(&body)
+
+	// Target is either an existing file, or a new file in a folder
+	session := &uploadSession{
+		conflict: body.Item.ConflictBehavior,
+		modified: body.Item.FileSystemInfo.LastModifiedDateTime,
+		expires:  time.Now().Add(time.Hour),
+	}
+	if len(missing) == 0 {
+		if it.folder || it.isRoot() {
+			writeError(w, http.StatusBadRequest, "invalidRequest", "Cannot upload to a folder.")
+			return
+		}
+		if session.conflict == "fail" {
+			writeError(w, http.StatusConflict, "nameAlreadyExists", "An item with the same name already exists.")
+			return
+		}
+		session.parent = it.parent
+		session.name = it.name
+	} else {
+		session.parent = it
+		session.missing = missing[:len(missing)-1]
+		session.name = missing[len(missing)-1]
+	}
+
+	id := s.newID()
+	s.sessions[id] = session
+	writeJSON(w, http.StatusOK, map[string]any{
+		"uploadUrl":          s.URL + "/upload/" + url.PathEscape(id) + "?tempauth=fake",
+		"expirationDateTime": session.expires.UTC().Format(time.RFC3339),
+		"nextExpectedRanges": session.nextExpectedRanges(),
+	})
+}
+
+func (s *Server) handleUpload(w http.ResponseWriter, r *http.Request, escapedID string) {
+	id, _ := url.PathUnescape(escapedID)
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	session := s.sessions[id]
+	if session == nil {
+		writeError(w, http.StatusNotFound, "itemNotFound", "Upload session not found.")
+		return
+	}
+
+	// Cancel session?
+	if r.Method == "DELETE" {
+		delete(s.sessions, id)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if r.Method != "PUT" {
+		writeError(w, http.StatusBadRequest, "invalidRequest", "Unsupported request.")
+		return
+	}
+
+	// Check range
+	var start, end, total int64
+	_, err := fmt.Sscanf(r.Header.Get("Content-Range"), "bytes %d-%d/%d", &start, &end, &total)
+	if err != nil || start != int64(len(session.data)) || end < start || end >= total {
+		writeError(w, http.StatusRequestedRangeNotSatisfiable, "invalidRange", "The uploaded fragment overlaps with data that has already been received.")
+		return
+	}
+	chunk, err := io.ReadAll(r.Body)
+	if err != nil || int64(len(chunk)) != end-start+1 {
+		writeError(w, http.StatusBadRequest, "invalidRequest", "Fragment length does not match Content-Range.")
+		return
+	}
+	session.data = append(session.data, chunk...)
+
+	// More to come?
+	if int64(len(session.data)) < total {
+		writeJSON(w, http.StatusAccepted, map[string]any{
+			"expirationDateTime": session.expires.UTC().Format(time.RFC3339),
+			"nextExpectedRanges": session.nextExpectedRanges(),
+		})
+		return
+	}
+
+	// Commit file
+	delete(s.sessions, id)
+	parent := s.mkdirAll(session.parent, session.missing)
+	name := session.name
+	existing := parent.child(name)
+	if existing != nil {
+		switch session.conflict {
+		case "fail":
+			writeError(w, http.StatusConflict, "nameAlreadyExists", "An item with the same name already exists.")
+			return
+		case "rename":
+			name = uniqueName(parent, name)
+			existing = nil
+		}
+	}
+	modified := session.modified
+	if modified.IsZero() {
+		modified = time.Now()
+	}
+	status := http.StatusCreated
+	if existing != nil {
+		status = http.StatusOK
+	}
+	writeJSON(w, status, s.writeFile(parent, name, session.data, modified).json(s))
+}
+
+// Finds a free name in parent, the same way OneDrive does: "name 1.ext", "name 2.ext", ...
+func uniqueName(parent *item, name string) string {
+	ext := path.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf("%s %d%s", base, i, ext)
+		if parent.child(candidate) == nil {
+			return candidate
+		}
+	}
+}
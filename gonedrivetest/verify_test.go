@@ -0,0 +1,109 @@
+package gonedrivetest_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/sukus21/gonedrive"
+	"github.com/sukus21/gonedrive/gonedrivetest"
+)
+
+// Downloads a file through NewVerifyingReader, and returns the error from reading it.
+func readVerified(c *gonedrive.Client, ref gonedrive.ItemRef) error {
+	ctx := context.Background()
+	item, err := c.GetDriveItem(ctx, ref, nil)
+	if err != nil {
+		return err
+	}
+	body, err := c.DownloadDriveItem(ctx, ref)
+	if err != nil {
+		return err
+	}
+	r := gonedrive.NewVerifyingReader(body, item)
+	defer r.Close()
+	_, err = io.Copy(io.Discard, r)
+	return err
+}
+
+func TestVerifyingReader(t *testing.T) {
+	s := gonedrivetest.NewServer()
+	defer s.Close()
+	s.AddFile("file.bin", downloadContent(10000))
+	ref := gonedrive.ItemByPath("file.bin")
+
+	if err := readVerified(s.NewClient(), ref); err != nil {
+		t.Errorf("intact download: %v", err)
+	}
+
+	// Truncated body
+	s.InjectFault(gonedrivetest.Fault{Path: "/content", Truncate: true, Times: 1})
+	if err := readVerified(s.NewClient(), ref); err == nil {
+		t.Error("truncated download: got no error")
+	}
+
+	// Content does not match the hash
+	c := s.NewClient(gonedrive.WithHTTPClient(&http.Client{Transport: wrongHashTransport{}}))
+	if err := readVerified(c, ref); !errors.Is(err, gonedrive.ErrHashMismatch) {
+		t.Errorf("corrupted download: got %v, want ErrHashMismatch", err)
+	}
+}
+
+func TestSyncFolderVerify(t *testing.T) {
+	s := gonedrivetest.NewServer()
+	defer s.Close()
+	s.AddFile("Sync/a.txt", []byte("first file"))
+	s.AddFile("Sync/b.txt", []byte("second file"))
+
+	// Counts errors, and checks that the bad file is gone.
+	// Events are sent from several goroutines.
+	mux := sync.Mutex{}
+	errorCount := 0
+	eventFn := func(event gonedrive.SyncEvent) {
+		if event, ok := event.(*gonedrive.SyncEventError); ok {
+			mux.Lock()
+			errorCount++
+			mux.Unlock()
+			if !errors.Is(event.Err, gonedrive.ErrHashMismatch) {
+				t.Errorf("got error %v, want ErrHashMismatch", event.Err)
+			}
+		}
+	}
+	checkRemoved := func(dir string) {
+		for _, name := range []string{"a.txt", "b.txt"} {
+			if _, err := os.Stat(filepath.Join(dir, name)); !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("%s was kept (%v)", name, err)
+			}
+		}
+	}
+	transport := &http.Client{Transport: wrongHashTransport{}}
+
+	// With Client
+	dir := t.TempDir()
+	c := s.NewClient(gonedrive.WithHTTPClient(transport), gonedrive.WithSyncVerify(true))
+	if err := c.SyncFolder(context.Background(), gonedrive.ItemByPath("Sync"), dir, nil, eventFn); err != nil {
+		t.Fatal(err)
+	}
+	if errorCount != 2 {
+		t.Errorf("got %d error events, want 2", errorCount)
+	}
+	checkRemoved(dir)
+
+	// With GraphToken
+	errorCount = 0
+	dir = t.TempDir()
+	token := s.Token()
+	token.SetHTTPClient(transport)
+	if err := token.SyncFolderVerify(context.Background(), "Sync", dir, nil, eventFn); err != nil {
+		t.Fatal(err)
+	}
+	if errorCount != 2 {
+		t.Errorf("got %d error events, want 2", errorCount)
+	}
+	checkRemoved(dir)
+}
@@ -0,0 +1,155 @@
+package gonedrive
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/sukus21/gonedrive/quickxor"
+)
+
+// Matches any *HashMismatchError, using errors.Is.
+var ErrHashMismatch = errors.New("hash mismatch")
+
+// Returned when downloaded content does not match what the API reported.
+type HashMismatchError struct {
+	// Name of the file.
+	Name string
+
+	// What did not match, such as "size", "quickXorHash" or "sha1Hash".
+	Algorithm string
+
+	// Value reported by the API, and value of the downloaded content.
+	Expected string
+	Actual   string
+}
+
+func (err *HashMismatchError) Error() string {
+	return fmt.Sprintf("%s mismatch for \"%s\": expected %s, got %s", err.Algorithm, err.Name, err.Expected, err.Actual)
+}
+
+func (err *HashMismatchError) Is(target error) bool {
+	return target == ErrHashMismatch
+}
+
+// A hash reported by the API, and the hasher to compare it with.
+type expectedHash struct {
+	algorithm string
+	expected  string
+	hasher    io.Writer
+	sum       func() string
+}
+
+// Hashes content as it is written, and checks it against an item.
+// Every hash the API reported for the item is checked.
+type hashVerifier struct {
+	item   *DriveItem
+	hashes []expectedHash
+	size   int64
+}
+
+func newHashVerifier(item *DriveItem) *hashVerifier {
+	v := &hashVerifier{item: item}
+	if item.File == nil || item.File.Hashes == nil {
+		return v
+	}
+	hashes := item.File.Hashes
+
+	if hashes.QuickXor != "" {
+		hasher := quickxor.NewHasher()
+		v.hashes = append(v.hashes, expectedHash{"quickXorHash", hashes.QuickXor, hasher, hasher.GetHashBase64})
+	}
+	if hashes.Sha1 != "" {
+		hasher := sha1.New()
+		v.hashes = append(v.hashes, expectedHash{"sha1Hash", strings.ToUpper(hashes.Sha1), hasher, func() string {
+			return strings.ToUpper(hex.EncodeToString(hasher.Sum(nil)))
+		}})
+	}
+	if hashes.Sha256 != "" {
+		hasher := sha256.New()
+		v.hashes = append(v.hashes, expectedHash{"sha256Hash", strings.ToUpper(hashes.Sha256), hasher, func() string {
+			return strings.ToUpper(hex.EncodeToString(hasher.Sum(nil)))
+		}})
+	}
+	return v
+}
+
+// Never returns errors, always returns full length
+func (v *hashVerifier) Write(p []byte) (int, error) {
+	for _, h := range v.hashes {
+		h.hasher.Write(p)
+	}
+	v.size += int64(len(p))
+	return len(p), nil
+}
+
+// Compares what has been written with the item.
+// Returns a *HashMismatchError on the first difference.
+func (v *hashVerifier) verify() error {
+	if v.size != v.item.Size {
+		return &HashMismatchError{
+			Name:      v.item.Name,
+			Algorithm: "size",
+			Expected:  fmt.Sprint(v.item.Size),
+			Actual:    fmt.Sprint(v.size),
+		}
+	}
+	for _, h := range v.hashes {
+		if actual := h.sum(); actual != h.expected {
+			return &HashMismatchError{
+				Name:      v.item.Name,
+				Algorithm: h.algorithm,
+				Expected:  h.expected,
+				Actual:    actual,
+			}
+		}
+	}
+	return nil
+}
+
+// Reads the content of a file, and checks it against the item.
+type verifyingReader struct {
+	r        io.ReadCloser
+	verifier *hashVerifier
+	err      error
+}
+
+// Wraps r, which reads the content of item, so the content is hashed with
+// QuickXorHash as it is read. Once r reaches EOF, the number of bytes read and
+// the hash are compared with item. If they do not match, Read returns a
+// *HashMismatchError instead of io.EOF.
+//
+// Use this with DownloadDriveItem to catch truncated or corrupted downloads.
+func NewVerifyingReader(r io.ReadCloser, item *DriveItem) io.ReadCloser {
+	verifier := newHashVerifier(item)
+	verifier.hashes = slices.DeleteFunc(verifier.hashes, func(h expectedHash) bool {
+		return h.algorithm != "quickXorHash"
+	})
+	return &verifyingReader{r: r, verifier: verifier}
+}
+
+func (r *verifyingReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	n, err := r.r.Read(p)
+	r.verifier.Write(p[:n])
+	if err == io.EOF {
+		if verifyErr := r.verifier.verify(); verifyErr != nil {
+			err = verifyErr
+		}
+	}
+	if err != nil {
+		r.err = err
+	}
+	return n, err
+}
+
+func (r *verifyingReader) Close() error {
+	return r.r.Close()
+}
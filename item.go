@@ -0,0 +1,129 @@
+package gonedrive
+
+import (
+	"path"
+	"strings"
+)
+
+// A special folder, which can be addressed without knowing its path or ID.
+type SpecialFolder string
+
+const (
+	SpecialFolder_Documents  = SpecialFolder("documents")
+	SpecialFolder_Photos     = SpecialFolder("photos")
+	SpecialFolder_CameraRoll = SpecialFolder("cameraroll")
+	SpecialFolder_AppRoot    = SpecialFolder("approot")
+	SpecialFolder_Music      = SpecialFolder("music")
+	SpecialFolder_Recordings = SpecialFolder("recordings")
+)
+
+// Reference to a drive item.
+// An item is found by ID, by special folder, or from the drive root,
+// optionally followed by a path below it.
+// The zero value refers to the root of the client's drive.
+//
+// IDs stay the same when an item is renamed or moved, paths do not.
+type ItemRef struct {
+	// Drive the item is in.
+	// If empty, the drive of the client is used.
+	Drive DriveRef
+
+	// ID of the item.
+	ID string
+
+	// Special folder, used if ID is empty.
+	Special SpecialFolder
+
+	// Slash separated path, relative to the item given by ID or Special,
+	// or to the drive root if neither is set.
+	Path string
+}
+
+// Item with the given ID.
+func ItemByID(id string) ItemRef {
+	return ItemRef{ID: id}
+}
+
+// Item at the given path, relative to the drive root.
+func ItemByPath(path string) ItemRef {
+	return ItemRef{Path: path}
+}
+
+// The given special folder.
+func ItemBySpecialFolder(folder SpecialFolder) ItemRef {
+	return ItemRef{Special: folder}
+}
+
+// Reference to the item at the given path below ref.
+func (ref ItemRef) Child(p string) ItemRef {
+	ref.Path = path.Join(ref.Path, p)
+	return ref
+}
+
+// Same reference, in the given drive.
+func (ref ItemRef) OnDrive(drive DriveRef) ItemRef {
+	ref.Drive = drive
+	return ref
+}
+
+// Human readable form of the reference, such as "a/b" or "items/{id}:/a/b".
+// Paths from the drive root are given without leading slash.
+func (ref ItemRef) String() string {
+	p := strings.Join(splitPath(ref.Path), "/")
+	base := ""
+	switch {
+	case ref.ID != "":
+		base = "items/" + ref.ID
+	case ref.Special != "":
+		base = "special/" + string(ref.Special)
+	default:
+		return p
+	}
+	if p != "" {
+		base += ":/" + p
+	}
+	return base
+}
+
+// Reference to the item by ID, in the drive it was found in.
+func (item *DriveItem) Ref() ItemRef {
+	ref := ItemByID(item.Id)
+	if item.ParentReference != nil && item.ParentReference.DriveId != "" {
+		ref.Drive = DriveByID(item.ParentReference.DriveId)
+	}
+	return ref
+}
+
+// Builds the endpoint of an item, such as "/me/drive/root:/a/b:/children".
+// The path is validated first, see ValidatePath.
+func (c *Client) itemEndpoint(ref ItemRef, action string) (string, error) {
+	if err := ValidatePath(ref.Path); err != nil {
+		return "", err
+	}
+
+	// Which drive?
+	o := c.drive
+	if ref.Drive != "" {
+		o = ref.Drive.path()
+	}
+
+	// Which item?
+	switch {
+	case ref.ID != "":
+		o += "/items/" + escapeID(ref.ID)
+	case ref.Special != "":
+		o += "/special/" + escapeID(string(ref.Special))
+	default:
+		o += "/root"
+	}
+	if p := escapePath(ref.Path); p != "" {
+		o += ":/" + p
+		if action != "" {
+			o += ":"
+		}
+	}
+	if action != "" {
+		o += "/" + action
+	}
+	return o, nil
+}
@@ -0,0 +1,55 @@
+package gonedrive
+
+import (
+	"context"
+	"iter"
+)
+
+// Iterates over the pages of a paginated collection, such as children,
+// delta, search, permissions or versions.
+// The first page is requested from endpoint, after which @odata.nextLink
+// is followed as is, until there are no more pages.
+//
+// Page size can be controlled with $top in the endpoint query.
+// If a request fails, the error is yielded and iteration stops.
+func Pages[T any](ctx context.Context, r Requester, endpoint string) iter.Seq2[*ResponsePaginated[[]T], error] {
+	return func(yield func(*ResponsePaginated[[]T], error) bool) {
+		var page *ResponsePaginated[[]T]
+		request, err := r.BuildRequestContext(ctx, "GET", endpoint, nil)
+		for {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			page, err = SendRequest[ResponsePaginated[[]T]](r, request)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(page, nil) || page.NextLink == "" {
+				return
+			}
+			request, err = r.BuildRequestRawContext(ctx, "GET", page.NextLink, nil)
+		}
+	}
+}
+
+// Same as Pages, but iterates over the values of every page instead.
+// Pages are only requested as the iteration gets to them,
+// so breaking out of the loop early saves requests.
+func Paginate[T any](ctx context.Context, r Requester, endpoint string) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for page, err := range Pages[T](ctx, r, endpoint) {
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, v := range page.Value {
+				if !yield(v, nil) {
+					return
+				}
+			}
+		}
+	}
+}
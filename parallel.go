@@ -0,0 +1,131 @@
+package gonedrive
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// Defaults for ParallelDownloadParams.
+const (
+	DefaultChunkSize   = 8 << 20
+	DefaultConnections = 4
+)
+
+type ParallelDownloadParams struct {
+	// Size of each range, in bytes.
+	// Defaults to DefaultChunkSize.
+	ChunkSize int64
+
+	// Number of ranges downloaded at the same time.
+	// Defaults to DefaultConnections.
+	Connections int
+
+	// Skip checking the content against the size and hashes of the item.
+	SkipVerify bool
+}
+
+// Downloads a file into w, fetching several ranges of it at the same time.
+// Each range is retried on its own, if its connection breaks.
+//
+// Unless params.SkipVerify is set, the content is checked against the size
+// and hashes reported by the API. If they do not match, a *HashMismatchError
+// is returned. Ranges are hashed in order as they complete, so at most one
+// range per connection is held in memory.
+func (c *Client) DownloadParallel(ctx context.Context, ref ItemRef, w io.WriterAt, params ParallelDownloadParams) (*DriveItem, error) {
+	if params.ChunkSize <= 0 {
+		params.ChunkSize = DefaultChunkSize
+	}
+	if params.Connections <= 0 {
+		params.Connections = DefaultConnections
+	}
+
+	// Stop all connections on the first error
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	remote, err := c.OpenFile(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	item := remote.Item()
+	chunks := (item.Size + params.ChunkSize - 1) / params.ChunkSize
+
+	// Shared between workers
+	var (
+		mux      sync.Mutex
+		turn     = sync.NewCond(&mux)
+		next     int64
+		hashed   int64
+		firstErr error
+		verifier = newHashVerifier(item)
+	)
+	fail := func(err error) {
+		mux.Lock()
+		defer mux.Unlock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+		turn.Broadcast()
+	}
+
+	// Start downloading
+	wg := sync.WaitGroup{}
+	for range min(int64(params.Connections), chunks) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			buf := make([]byte, params.ChunkSize)
+			for {
+				// Claim a range
+				mux.Lock()
+				chunk := next
+				next++
+				stop := firstErr != nil
+				mux.Unlock()
+				if stop || chunk >= chunks {
+					return
+				}
+
+				// Download range
+				off := chunk * params.ChunkSize
+				p := buf[:min(params.ChunkSize, item.Size-off)]
+				if _, err := remote.fetch(p, off); err != nil {
+					fail(err)
+					return
+				}
+				if _, err := w.WriteAt(p, off); err != nil {
+					fail(err)
+					return
+				}
+				if params.SkipVerify {
+					continue
+				}
+
+				// Hash ranges in order
+				mux.Lock()
+				for hashed != chunk && firstErr == nil {
+					turn.Wait()
+				}
+				if firstErr == nil {
+					verifier.Write(p)
+					hashed++
+					turn.Broadcast()
+				}
+				mux.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Did it work?
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if !params.SkipVerify {
+		if err := verifier.verify(); err != nil {
+			return nil, err
+		}
+	}
+	return item, nil
+}
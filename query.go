@@ -0,0 +1,173 @@
+package gonedrive
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// OData query options for a request, such as $select and $expand.
+// Methods modify the query and return it, so calls can be chained:
+//
+//	gonedrive.NewQuery().Select("id", "name", "size", "file").Top(100)
+//
+// A nil *Query is an empty query.
+type Query struct {
+	selects   []string
+	expands   []queryExpand
+	filter    string
+	orderBy   []string
+	top       int
+	skipToken string
+	count     bool
+	raw       []string
+}
+
+type queryExpand struct {
+	relation string
+	nested   *Query
+}
+
+func NewQuery() *Query {
+	return &Query{}
+}
+
+// Query made of already encoded options, such as "$top=10".
+// Used to support the old string based queries.
+func RawQuery(options ...string) *Query {
+	return &Query{raw: options}
+}
+
+// Only return these properties.
+func (q *Query) Select(properties ...string) *Query {
+	q.selects = append(q.selects, properties...)
+	return q
+}
+
+// Include a related resource, such as "children" or "thumbnails".
+// nested holds query options for the related resource, and may be nil.
+// Only $select, $expand, $filter, $orderby and $top apply to nested queries.
+func (q *Query) Expand(relation string, nested *Query) *Query {
+	q.expands = append(q.expands, queryExpand{relation, nested})
+	return q
+}
+
+// Only return results matching the filter expression, such as "file ne null".
+func (q *Query) Filter(filter string) *Query {
+	q.filter = filter
+	return q
+}
+
+// Sort results by a property.
+// Can be called several times to sort by more properties.
+func (q *Query) OrderBy(property string, descending bool) *Query {
+	if descending {
+		property += " desc"
+	}
+	q.orderBy = append(q.orderBy, property)
+	return q
+}
+
+// Page size for collections.
+func (q *Query) Top(n int) *Query {
+	q.top = n
+	return q
+}
+
+// Continue a collection from a previous page.
+// Usually not needed, as pagination follows @odata.nextLink instead.
+func (q *Query) SkipToken(token string) *Query {
+	q.skipToken = token
+	return q
+}
+
+// Include the total number of results in the response.
+func (q *Query) Count(count bool) *Query {
+	q.count = count
+	return q
+}
+
+// Returns options as key/value pairs, without encoding.
+func (q *Query) options() [][2]string {
+	options := [][2]string{}
+	if len(q.selects) != 0 {
+		options = append(options, [2]string{"$select", strings.Join(q.selects, ",")})
+	}
+	if len(q.expands) != 0 {
+		expands := make([]string, len(q.expands))
+		for i, e := range q.expands {
+			expands[i] = e.relation
+			if nested := e.nested.nestedString(); nested != "" {
+				expands[i] += "(" + nested + ")"
+			}
+		}
+		options = append(options, [2]string{"$expand", strings.Join(expands, ",")})
+	}
+	if q.filter != "" {
+		options = append(options, [2]string{"$filter", q.filter})
+	}
+	if len(q.orderBy) != 0 {
+		options = append(options, [2]string{"$orderby", strings.Join(q.orderBy, ",")})
+	}
+	if q.top > 0 {
+		options = append(options, [2]string{"$top", strconv.Itoa(q.top)})
+	}
+	if q.skipToken != "" {
+		options = append(options, [2]string{"$skipToken", q.skipToken})
+	}
+	if q.count {
+		options = append(options, [2]string{"$count", "true"})
+	}
+	return options
+}
+
+// Nested options are separated by semicolons, and encoded along with the outer option.
+func (q *Query) nestedString() string {
+	if q == nil {
+		return ""
+	}
+	parts := []string{}
+	for _, option := range q.options() {
+		parts = append(parts, option[0]+"="+option[1])
+	}
+	return strings.Join(parts, ";")
+}
+
+// Encodes the query for use in a URL, without the leading "?".
+func (q *Query) Encode() string {
+	if q == nil {
+		return ""
+	}
+	parts := []string{}
+	for _, option := range q.options() {
+		parts = append(parts, option[0]+"="+escapeQueryValue(option[1]))
+	}
+	parts = append(parts, q.raw...)
+	return strings.Join(parts, "&")
+}
+
+// Escapes a query value, leaving characters common in OData expressions
+// readable where RFC 3986 allows it. "&", "+", "#" and "%" are always escaped.
+func escapeQueryValue(value string) string {
+	var sb strings.Builder
+	for _, b := range []byte(value) {
+		if 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z' || '0' <= b && b <= '9' || strings.IndexByte("-._~$,()';=:/@", b) != -1 {
+			sb.WriteByte(b)
+		} else {
+			fmt.Fprintf(&sb, "%%%02X", b)
+		}
+	}
+	return sb.String()
+}
+
+func (q *Query) String() string {
+	return q.Encode()
+}
+
+// Encoded query with a leading "?", or nothing if the query is empty.
+func (q *Query) suffix() string {
+	if encoded := q.Encode(); encoded != "" {
+		return "?" + encoded
+	}
+	return ""
+}
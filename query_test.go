@@ -0,0 +1,47 @@
+package gonedrive
+
+import "testing"
+
+func TestQueryEncode(t *testing.T) {
+	tests := []struct {
+		query *Query
+		want  string
+	}{
+		{nil, ""},
+		{NewQuery(), ""},
+		{NewQuery().Select("id", "name"), "$select=id,name"},
+		{NewQuery().Expand("children", nil), "$expand=children"},
+		{
+			NewQuery().Expand("children", NewQuery().Select("id", "name").Top(5)).Expand("thumbnails", nil),
+			"$expand=children($select=id,name;$top=5),thumbnails",
+		},
+		{NewQuery().Filter("name eq 'a&b #1'"), "$filter=name%20eq%20'a%26b%20%231'"},
+		{NewQuery().Filter("size gt 100+5%"), "$filter=size%20gt%20100%2B5%25"},
+		{NewQuery().OrderBy("name", false).OrderBy("size", true), "$orderby=name,size%20desc"},
+		{NewQuery().Top(0), ""},
+		{NewQuery().Top(10), "$top=10"},
+		{NewQuery().SkipToken("abc=="), "$skipToken=abc=="},
+		{NewQuery().Count(true), "$count=true"},
+		{
+			NewQuery().Count(true).Top(10).Select("id").Filter("file ne null"),
+			"$select=id&$filter=file%20ne%20null&$top=10&$count=true",
+		},
+		{RawQuery("$top=10", "$select=id"), "$top=10&$select=id"},
+		{RawQuery("$top=10").Select("id"), "$select=id&$top=10"},
+	}
+	for _, test := range tests {
+		if got := test.query.Encode(); got != test.want {
+			t.Errorf("got %q, want %q", got, test.want)
+		}
+	}
+}
+
+func TestQuerySuffix(t *testing.T) {
+	var q *Query
+	if got := q.suffix(); got != "" {
+		t.Errorf("nil query suffix: got %q", got)
+	}
+	if got := NewQuery().Top(1).suffix(); got != "?$top=1" {
+		t.Errorf("got %q, want %q", got, "?$top=1")
+	}
+}
@@ -4,12 +4,31 @@ import (
 	"bytes"
 	"encoding/base64"
 	"encoding/binary"
+	"errors"
+	"hash"
 )
 
 const bitsInLastCell = 32
 const shift = 11
 const widthInBits = 160
 
+// Size of a QuickXorHash in bytes.
+const Size = widthInBits / 8
+
+// Preferred number of bytes per Write.
+// Any size works, this is only a hint.
+const BlockSize = 64
+
+// Prefix of the state returned by MarshalBinary, followed by a format version.
+const magic = "qxor\x01"
+
+// Length of the state returned by MarshalBinary.
+const marshaledSize = len(magic) + 3*8 + 8 + 8
+
+var ErrInvalidState = errors.New("quickxor: invalid hash state")
+
+var _ hash.Hash = (*Hasher)(nil)
+
 type Hasher struct {
 	data        []uint64
 	lengthSoFar int64
@@ -102,6 +121,67 @@ func (qxor *Hasher) hashFinal() []byte {
 	return rgb
 }
 
+// Appends the current hash to b, and returns the result.
+// The state of the hasher is not changed.
+func (qxor *Hasher) Sum(b []byte) []byte {
+	return append(b, qxor.hashFinal()...)
+}
+
+// Resets the hasher to its initial state.
+func (qxor *Hasher) Reset() {
+	*qxor = *NewHasher()
+}
+
+// Returns Size.
+func (qxor *Hasher) Size() int {
+	return Size
+}
+
+// Returns BlockSize.
+func (qxor *Hasher) BlockSize() int {
+	return BlockSize
+}
+
+// Saves the state of the hasher, so hashing can be continued later.
+// The state is restored with UnmarshalBinary.
+func (qxor *Hasher) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, 0, marshaledSize)
+	buf = append(buf, magic...)
+	for _, cell := range qxor.data {
+		buf = binary.BigEndian.AppendUint64(buf, cell)
+	}
+	buf = binary.BigEndian.AppendUint64(buf, uint64(qxor.shiftSoFar))
+	buf = binary.BigEndian.AppendUint64(buf, uint64(qxor.lengthSoFar))
+	return buf, nil
+}
+
+// Restores a state saved by MarshalBinary.
+// Returns ErrInvalidState if the state is malformed.
+func (qxor *Hasher) UnmarshalBinary(state []byte) error {
+	if len(state) != marshaledSize || string(state[:len(magic)]) != magic {
+		return ErrInvalidState
+	}
+	state = state[len(magic):]
+
+	// Read state
+	data := make([]uint64, (widthInBits-1)/64+1)
+	for i := range data {
+		data[i] = binary.BigEndian.Uint64(state)
+		state = state[8:]
+	}
+	shiftSoFar := binary.BigEndian.Uint64(state)
+	lengthSoFar := int64(binary.BigEndian.Uint64(state[8:]))
+	if shiftSoFar >= widthInBits || lengthSoFar < 0 {
+		return ErrInvalidState
+	}
+
+	// All good
+	qxor.data = data
+	qxor.shiftSoFar = int(shiftSoFar)
+	qxor.lengthSoFar = lengthSoFar
+	return nil
+}
+
 func (qxor *Hasher) GetHash() []byte {
 	return qxor.hashFinal()
 }
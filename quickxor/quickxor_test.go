@@ -0,0 +1,117 @@
+package quickxor
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// Deterministic test data, long enough to wrap around the hash width several times.
+func testData() []byte {
+	data := make([]byte, 1000)
+	for i := range data {
+		data[i] = byte(i*7 + i/13)
+	}
+	return data
+}
+
+func TestEmpty(t *testing.T) {
+	if got := QuickXorHashBase64(nil); got != "AAAAAAAAAAAAAAAAAAAAAAAAAAA=" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestSplitWrites(t *testing.T) {
+	data := testData()
+	want := QuickXorHash(data)
+	for _, split := range []int{0, 1, 159, 160, 161, 777, len(data) - 1} {
+		qxor := NewHasher()
+		qxor.Write(data[:split])
+		qxor.Write(data[split:])
+		if got := qxor.Sum(nil); !bytes.Equal(got, want) {
+			t.Errorf("split at %d: got %x, want %x", split, got, want)
+		}
+	}
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	data := testData()
+	want := QuickXorHash(data)
+	for _, split := range []int{0, 1, 159, 160, 161, 777, len(data) - 1} {
+		first := NewHasher()
+		first.Write(data[:split])
+		state, err := first.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(state) != marshaledSize {
+			t.Fatalf("state is %d bytes, want %d", len(state), marshaledSize)
+		}
+
+		// Continue in a hasher that has seen other data
+		second := NewHasher()
+		second.Write([]byte("garbage"))
+		if err := second.UnmarshalBinary(state); err != nil {
+			t.Fatalf("split at %d: %v", split, err)
+		}
+		second.Write(data[split:])
+		if got := second.Sum(nil); !bytes.Equal(got, want) {
+			t.Errorf("split at %d: got %x, want %x", split, got, want)
+		}
+	}
+}
+
+func TestUnmarshalInvalid(t *testing.T) {
+	qxor := NewHasher()
+	qxor.Write(testData())
+	state, _ := qxor.MarshalBinary()
+
+	badMagic := bytes.Clone(state)
+	badMagic[0] = 'x'
+	badShift := bytes.Clone(state)
+	badShift[len(badShift)-9] = 160
+	badLength := bytes.Clone(state)
+	badLength[len(badLength)-8] = 0x80
+
+	tests := map[string][]byte{
+		"empty":      nil,
+		"short":      state[:len(state)-1],
+		"long":       append(bytes.Clone(state), 0),
+		"bad magic":  badMagic,
+		"bad shift":  badShift,
+		"bad length": badLength,
+	}
+	for name, state := range tests {
+		restored := NewHasher()
+		if err := restored.UnmarshalBinary(state); !errors.Is(err, ErrInvalidState) {
+			t.Errorf("%s: got %v, want ErrInvalidState", name, err)
+		}
+		if !bytes.Equal(restored.Sum(nil), QuickXorHash(nil)) {
+			t.Errorf("%s: hasher was changed", name)
+		}
+	}
+}
+
+func TestSumAndReset(t *testing.T) {
+	data := testData()
+	qxor := NewHasher()
+	qxor.Write(data)
+
+	// Sum appends, and leaves the state alone
+	prefix := []byte("prefix")
+	sum := qxor.Sum(bytes.Clone(prefix))
+	if !bytes.Equal(sum[:len(prefix)], prefix) || !bytes.Equal(sum[len(prefix):], QuickXorHash(data)) {
+		t.Errorf("got %x", sum)
+	}
+	if !bytes.Equal(qxor.Sum(nil), QuickXorHash(data)) {
+		t.Error("Sum changed the state")
+	}
+
+	qxor.Reset()
+	if !bytes.Equal(qxor.Sum(nil), QuickXorHash(nil)) {
+		t.Error("Reset did not clear the state")
+	}
+	if qxor.Size() != Size || len(qxor.Sum(nil)) != Size {
+		t.Errorf("Size() = %d, sum is %d bytes", qxor.Size(), len(qxor.Sum(nil)))
+	}
+}
@@ -1,51 +1,116 @@
 package gonedrive
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"iter"
+	"net/url"
 	"strings"
 )
 
 // Get information about a single drive item
-func (t *GraphToken) GetDriveItem(path string, query ...string) (*DriveItem, error) {
-	urlpath := EndpointPath(path, "")
-	return MakeRequest[DriveItem](t, "GET", "/me/drive/"+urlpath, nil)
+func (c *Client) GetDriveItem(ctx context.Context, item ItemRef, query *Query) (*DriveItem, error) {
+	endpoint, err := c.itemEndpoint(item, "")
+	if err != nil {
+		return nil, err
+	}
+	return MakeRequestContext[DriveItem](ctx, c, "GET", endpoint+query.suffix(), nil)
 }
 
 // Runs a query to get all DriveItems within a folder.
 // This returns the paginated response structure.
-// Path should be WITHOUT leading/trailing slashes.
-func (t *GraphToken) GetDriveItemChildren(path string, query []string) (*ResponsePaginated[[]*DriveItem], error) {
-	up := EndpointPath(path, "children", query...)
-	return MakeRequest[ResponsePaginated[[]*DriveItem]](t, "GET", "/me/drive/"+up, nil)
+func (c *Client) GetDriveItemChildren(ctx context.Context, folder ItemRef, query *Query) (*ResponsePaginated[[]*DriveItem], error) {
+	endpoint, err := c.itemEndpoint(folder, "children")
+	if err != nil {
+		return nil, err
+	}
+	return MakeRequestContext[ResponsePaginated[[]*DriveItem]](ctx, c, "GET", endpoint+query.suffix(), nil)
 }
 
 // Lists all files in a given folder.
-// Path should be WITHOUT leading/trailing slashes.
-func (t *GraphToken) ListFolder(path string) ([]*DriveItem, error) {
-	songlist := make([]*DriveItem, 0, 1024)
-	query := []string{}
-
-	for {
-		resp, err := t.GetDriveItemChildren(path, query)
+func (c *Client) ListFolder(ctx context.Context, folder ItemRef, query *Query) ([]*DriveItem, error) {
+	songlist := []*DriveItem{}
+	for item, err := range c.Children(ctx, folder, query) {
 		if err != nil {
 			return nil, err
 		}
-		songlist = append(songlist, resp.Value...)
-		if resp.NextLink == "" {
-			return songlist, nil
+		songlist = append(songlist, item)
+	}
+	return songlist, nil
+}
+
+// Iterates over all DriveItems within a folder.
+// Pages are requested as needed, use Query.Top to set the page size.
+func (c *Client) Children(ctx context.Context, folder ItemRef, query *Query) iter.Seq2[*DriveItem, error] {
+	endpoint, err := c.itemEndpoint(folder, "children")
+	if err != nil {
+		return func(yield func(*DriveItem, error) bool) {
+			yield(nil, err)
 		}
-		split := strings.SplitN(resp.NextLink, "?", 2)
-		query = split[1:]
 	}
+	return Paginate[*DriveItem](ctx, c, endpoint+query.suffix())
+}
+
+// Iterates over the DriveItems matching a search query.
+// This searches file names, metadata and contents.
+func (c *Client) Search(ctx context.Context, search string, query *Query) iter.Seq2[*DriveItem, error] {
+	endpoint := fmt.Sprintf("%s/root/search(q='%s')", c.drive, url.PathEscape(strings.ReplaceAll(search, "'", "''")))
+	return Paginate[*DriveItem](ctx, c, endpoint+query.suffix())
 }
 
-// Downloads a DriveItem, and returns the file body.
+// Downloads a file, and returns the file body.
+// Use DriveItem.Ref to download an item that has already been looked up.
 // It is the responsibility of the caller to close the resulting reader.
-func (t *GraphToken) DownloadDriveItem(item *DriveItem) (io.ReadCloser, error) {
-	response, err := t.MakeRequest("GET", fmt.Sprintf("/me/drive/items/%s/content", item.Id), nil)
+// Cancelling ctx aborts the download, even while the body is being read.
+func (c *Client) DownloadDriveItem(ctx context.Context, item ItemRef) (io.ReadCloser, error) {
+	endpoint, err := c.itemEndpoint(item, "content")
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.MakeRequestContext(ctx, "GET", endpoint, nil)
 	if err != nil {
 		return nil, err
 	}
 	return response.Body, nil
 }
+
+// Same as Client.GetDriveItem.
+func (t *GraphToken) GetDriveItem(path string, query ...string) (*DriveItem, error) {
+	return t.GetDriveItemContext(context.Background(), path, query...)
+}
+
+// Same as Client.GetDriveItem.
+func (t *GraphToken) GetDriveItemContext(ctx context.Context, path string, query ...string) (*DriveItem, error) {
+	return t.client().GetDriveItem(ctx, ItemByPath(path), RawQuery(query...))
+}
+
+// Same as Client.GetDriveItemChildren.
+func (t *GraphToken) GetDriveItemChildren(path string, query []string) (*ResponsePaginated[[]*DriveItem], error) {
+	return t.GetDriveItemChildrenContext(context.Background(), path, query)
+}
+
+// Same as Client.GetDriveItemChildren.
+func (t *GraphToken) GetDriveItemChildrenContext(ctx context.Context, path string, query []string) (*ResponsePaginated[[]*DriveItem], error) {
+	return t.client().GetDriveItemChildren(ctx, ItemByPath(path), RawQuery(query...))
+}
+
+// Same as Client.ListFolder.
+func (t *GraphToken) ListFolder(path string) ([]*DriveItem, error) {
+	return t.ListFolderContext(context.Background(), path)
+}
+
+// Same as Client.ListFolder.
+func (t *GraphToken) ListFolderContext(ctx context.Context, path string) ([]*DriveItem, error) {
+	return t.client().ListFolder(ctx, ItemByPath(path), nil)
+}
+
+// Same as Client.DownloadDriveItem.
+func (t *GraphToken) DownloadDriveItem(item *DriveItem) (io.ReadCloser, error) {
+	return t.DownloadDriveItemContext(context.Background(), item)
+}
+
+// Same as Client.DownloadDriveItem.
+func (t *GraphToken) DownloadDriveItemContext(ctx context.Context, item *DriveItem) (io.ReadCloser, error) {
+	return t.client().DownloadDriveItem(ctx, item.Ref())
+}
@@ -1,48 +1,139 @@
 package gonedrive
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
 )
 
+// Anything that can build and send requests to the MS graph API.
+// Implemented by both *Client and *GraphToken.
+type Requester interface {
+	BuildRequestRawContext(ctx context.Context, method string, uri string, body io.Reader) (*http.Request, error)
+	BuildRequestContext(ctx context.Context, method string, endpoint string, body io.Reader) (*http.Request, error)
+	SendRequest(request *http.Request) (*http.Response, error)
+}
+
 // Builds a request object.
 // Authorization is taken care of when the request is returned.
-func (t *GraphToken) BuildRequestRaw(method string, uri string, body io.Reader) (*http.Request, error) {
-	request, err := http.NewRequest(method, uri, body)
+func (c *Client) BuildRequestRaw(method string, uri string, body io.Reader) (*http.Request, error) {
+	return c.BuildRequestRawContext(context.Background(), method, uri, body)
+}
+
+// Same as BuildRequestRaw, but the request is bound to ctx.
+// Cancelling ctx aborts the request once sent.
+func (c *Client) BuildRequestRawContext(ctx context.Context, method string, uri string, body io.Reader) (*http.Request, error) {
+	request, err := http.NewRequestWithContext(ctx, method, uri, body)
 	if err != nil {
 		return nil, err
 	}
-	request.Header.Add("Authorization", "Bearer "+t.AccessToken)
+	setGetBody(request, body)
+	if c.userAgent != "" {
+		request.Header.Set("User-Agent", c.userAgent)
+	}
+	if c.tokens != nil {
+		token, err := c.tokens.Token(request.Context())
+		if err != nil {
+			return nil, err
+		}
+		request.Header.Add("Authorization", "Bearer "+token)
+	}
 	return request, nil
 }
 
 // Builds a request object.
 // You supply the method, the endpoint (/me/drive/*) and the request body.
 // Authorization is taken care of when the request is returned.
-func (t *GraphToken) BuildRequest(method string, endpoint string, body io.Reader) (*http.Request, error) {
-	return t.BuildRequestRaw(method, "https://graph.microsoft.com/v1.0"+endpoint, body)
+func (c *Client) BuildRequest(method string, endpoint string, body io.Reader) (*http.Request, error) {
+	return c.BuildRequestContext(context.Background(), method, endpoint, body)
+}
+
+// Same as BuildRequest, but the request is bound to ctx.
+func (c *Client) BuildRequestContext(ctx context.Context, method string, endpoint string, body io.Reader) (*http.Request, error) {
+	return c.BuildRequestRawContext(ctx, method, c.baseURL+endpoint, body)
 }
 
 // Sends a request object to the MS graph API.
 // Returns error responses from the API as a go error.
-func (t *GraphToken) SendRequest(request *http.Request) (*http.Response, error) {
-	response, err := http.DefaultClient.Do(request)
+// The request is aborted if its context is cancelled.
+// Failed requests are retried according to the client's RetryPolicy.
+//
+// If the API rejects the access token, it is refreshed,
+// and the request is sent once more.
+func (c *Client) SendRequest(request *http.Request) (*http.Response, error) {
+	response, err := c.sendRetrying(request)
+	if !isInvalidToken(err) || c.tokens == nil {
+		return nilOnError(response, err)
+	}
+
+	// Only authorized requests with rewindable bodies can be resent
+	stale, ok := strings.CutPrefix(request.Header.Get("Authorization"), "Bearer ")
+	if !ok || (request.Body != nil && request.GetBody == nil) {
+		return nil, err
+	}
+	c.warn("access token rejected, refreshing", "method", request.Method, "url", logURL(request.URL))
+	c.tokens.Invalidate(stale)
+	token, err := c.tokens.Token(request.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	// Try again with new token
+	retry := request.Clone(request.Context())
+	if request.GetBody != nil {
+		if retry.Body, err = request.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	retry.Header.Set("Authorization", "Bearer "+token)
+	return nilOnError(c.sendRetrying(retry))
+}
+
+// Error responses are passed along internally, so the retry logic can
+// look at them. Callers only get the error.
+func nilOnError(response *http.Response, err error) (*http.Response, error) {
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// Strips the query from URLs before logging them,
+// since pre-authenticated URLs carry their credentials there.
+func logURL(u *url.URL) string {
+	stripped := *u
+	stripped.RawQuery = ""
+	return stripped.Redacted()
+}
+
+// Reports whether err is the API rejecting the access token.
+func isInvalidToken(err error) bool {
+	var errResp *ErrorResponse
+	return errors.As(err, &errResp) && errResp.Outer.Code == "InvalidAuthenticationToken"
+}
+
+// Sends the request once.
+// Error responses are returned as an *ErrorResponse, along with the
+// response itself. The body of that response has already been consumed.
+func (c *Client) sendRequest(request *http.Request) (*http.Response, error) {
+	response, err := c.httpClient.Do(request)
 	if err != nil {
 		return nil, err
 	}
+	c.debug("request sent", "method", request.Method, "url", logURL(request.URL), "status", response.StatusCode)
 
 	// Error response?
-	if response.StatusCode > 202 {
+	if response.StatusCode >= 300 {
 		defer response.Body.Close()
 		responseBody, err := io.ReadAll(response.Body)
 		if err != nil {
 			return nil, err
 		}
-
-		err = &ErrorResponse{}
-		json.Unmarshal(responseBody, err)
-		return nil, err
+		return response, newErrorResponse(response, responseBody)
 	}
 
 	// All good
@@ -54,19 +145,59 @@ func (t *GraphToken) SendRequest(request *http.Request) (*http.Response, error)
 // Returns error responses from the API as a go error.
 //
 // Only the first value of contentType is used, the rest are ignored.
-func (t *GraphToken) MakeRequest(method string, url string, body io.Reader, contentType ...string) (*http.Response, error) {
-	req, err := t.BuildRequest(method, url, body)
+func (c *Client) MakeRequest(method string, url string, body io.Reader, contentType ...string) (*http.Response, error) {
+	return c.MakeRequestContext(context.Background(), method, url, body, contentType...)
+}
+
+// Same as MakeRequest, but the request is bound to ctx.
+func (c *Client) MakeRequestContext(ctx context.Context, method string, url string, body io.Reader, contentType ...string) (*http.Response, error) {
+	req, err := c.BuildRequestContext(ctx, method, url, body)
 	if err != nil {
 		return nil, err
 	}
 	if len(contentType) != 0 {
 		req.Header.Add("Content-Type", contentType[0])
 	}
-	return t.SendRequest(req)
+	return c.SendRequest(req)
+}
+
+// Same as Client.BuildRequestRaw.
+func (t *GraphToken) BuildRequestRaw(method string, uri string, body io.Reader) (*http.Request, error) {
+	return t.client().BuildRequestRaw(method, uri, body)
+}
+
+// Same as Client.BuildRequestRawContext.
+func (t *GraphToken) BuildRequestRawContext(ctx context.Context, method string, uri string, body io.Reader) (*http.Request, error) {
+	return t.client().BuildRequestRawContext(ctx, method, uri, body)
 }
 
-func SendRequest[T any](t *GraphToken, request *http.Request) (*T, error) {
-	response, err := t.SendRequest(request)
+// Same as Client.BuildRequest.
+func (t *GraphToken) BuildRequest(method string, endpoint string, body io.Reader) (*http.Request, error) {
+	return t.client().BuildRequest(method, endpoint, body)
+}
+
+// Same as Client.BuildRequestContext.
+func (t *GraphToken) BuildRequestContext(ctx context.Context, method string, endpoint string, body io.Reader) (*http.Request, error) {
+	return t.client().BuildRequestContext(ctx, method, endpoint, body)
+}
+
+// Same as Client.SendRequest.
+func (t *GraphToken) SendRequest(request *http.Request) (*http.Response, error) {
+	return t.client().SendRequest(request)
+}
+
+// Same as Client.MakeRequest.
+func (t *GraphToken) MakeRequest(method string, url string, body io.Reader, contentType ...string) (*http.Response, error) {
+	return t.client().MakeRequest(method, url, body, contentType...)
+}
+
+// Same as Client.MakeRequestContext.
+func (t *GraphToken) MakeRequestContext(ctx context.Context, method string, url string, body io.Reader, contentType ...string) (*http.Response, error) {
+	return t.client().MakeRequestContext(ctx, method, url, body, contentType...)
+}
+
+func SendRequest[T any](r Requester, request *http.Request) (*T, error) {
+	response, err := r.SendRequest(request)
 	if err != nil {
 		return nil, err
 	}
@@ -78,8 +209,11 @@ func SendRequest[T any](t *GraphToken, request *http.Request) (*T, error) {
 		return nil, err
 	}
 
-	// Decode body
+	// Decode body, if any
 	dec := new(T)
+	if len(responseBody) == 0 {
+		return dec, nil
+	}
 	err = json.Unmarshal(responseBody, dec)
 	return dec, err
 }
@@ -88,11 +222,16 @@ func SendRequest[T any](t *GraphToken, request *http.Request) (*T, error) {
 // You supply the method, the endpoint (/me/drive/*) and the request body.
 // Returns error responses from the API as a go error.
 // Deserializes the result to whatever you want, using json.Unmarshal.
-func MakeRequest[T any](t *GraphToken, method string, url string, requestBody io.Reader) (*T, error) {
-	request, err := t.BuildRequest(method, url, requestBody)
+func MakeRequest[T any](r Requester, method string, url string, requestBody io.Reader) (*T, error) {
+	return MakeRequestContext[T](context.Background(), r, method, url, requestBody)
+}
+
+// Same as MakeRequest, but the request is bound to ctx.
+func MakeRequestContext[T any](ctx context.Context, r Requester, method string, url string, requestBody io.Reader) (*T, error) {
+	request, err := r.BuildRequestContext(ctx, method, url, requestBody)
 	if err != nil {
 		return nil, err
 	}
 
-	return SendRequest[T](t, request)
+	return SendRequest[T](r, request)
 }
@@ -0,0 +1,205 @@
+package gonedrive
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Controls how failed requests are retried.
+// Requests are retried when throttled (429), and idempotent requests are
+// also retried on server errors (500, 502, 503, 504) and network errors.
+// Requests with bodies are only retried if the body can be rewound.
+type RetryPolicy struct {
+	// Maximum number of attempts, including the first one.
+	// 1 or less disables retries.
+	MaxAttempts int
+
+	// No more retries are made once this much time has passed since the first attempt.
+	// 0 means no limit.
+	MaxElapsed time.Duration
+
+	// Delay before the first retry, doubled for each retry after that.
+	// A Retry-After header from the server takes precedence.
+	BaseDelay time.Duration
+
+	// Upper limit for the delay between retries.
+	MaxDelay time.Duration
+
+	// Called before waiting for each retry.
+	OnRetry func(event RetryEvent)
+}
+
+// Information about a retry, passed to RetryPolicy.OnRetry.
+type RetryEvent struct {
+	Request *http.Request
+
+	// The attempt that failed, starting at 1.
+	Attempt int
+
+	// Status code of the failed attempt, 0 if there was no response.
+	StatusCode int
+
+	// Error of the failed attempt.
+	Err error
+
+	// How long until the next attempt.
+	Delay time.Duration
+}
+
+// Retry policy used by clients unless something else is set.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 5,
+	MaxElapsed:  2 * time.Minute,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    30 * time.Second,
+}
+
+// Sets the retry policy for requests.
+// Defaults to DefaultRetryPolicy.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *Client) {
+		c.retry = &policy
+	}
+}
+
+// Sends a request, retrying it as described by the retry policy.
+func (c *Client) sendRetrying(request *http.Request) (*http.Response, error) {
+	policy := c.retry
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		response, err := c.sendRequest(request)
+		if err == nil || attempt >= policy.MaxAttempts || !shouldRetry(request, response, err) {
+			return response, err
+		}
+
+		// How long to wait?
+		delay := policy.backoff(attempt)
+		if response != nil {
+			if retryAfter, ok := parseRetryAfter(response.Header.Get("Retry-After")); ok {
+				delay = retryAfter
+			}
+		}
+		if policy.MaxElapsed > 0 && time.Since(start)+delay > policy.MaxElapsed {
+			return response, err
+		}
+
+		// Rewind body
+		next := request.Clone(request.Context())
+		if request.GetBody != nil {
+			body, bodyErr := request.GetBody()
+			if bodyErr != nil {
+				return response, err
+			}
+			next.Body = body
+		}
+
+		// Report retry
+		event := RetryEvent{
+			Request: request,
+			Attempt: attempt,
+			Err:     err,
+			Delay:   delay,
+		}
+		if response != nil {
+			event.StatusCode = response.StatusCode
+		}
+		c.warn("request failed, retrying", "method", request.Method, "url", logURL(request.URL), "attempt", attempt, "delay", delay, "error", err)
+		if policy.OnRetry != nil {
+			policy.OnRetry(event)
+		}
+
+		// Wait for it...
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-request.Context().Done():
+			timer.Stop()
+			return nil, request.Context().Err()
+		}
+		request = next
+	}
+}
+
+// Reports whether a failed attempt should be retried.
+func shouldRetry(request *http.Request, response *http.Response, err error) bool {
+	// Body cannot be sent again
+	if request.Body != nil && request.Body != http.NoBody && request.GetBody == nil {
+		return false
+	}
+
+	// Network error
+	if response == nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return false
+		}
+		return isIdempotent(request.Method)
+	}
+
+	// Server error
+	switch response.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return isIdempotent(request.Method)
+	}
+	return false
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+		return true
+	}
+	return false
+}
+
+// Jittered exponential backoff for the given attempt.
+func (policy *RetryPolicy) backoff(attempt int) time.Duration {
+	delay := policy.BaseDelay << (attempt - 1)
+	if delay <= 0 || (policy.MaxDelay > 0 && delay > policy.MaxDelay) {
+		delay = policy.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// Parses a Retry-After header, which is either a number of seconds or a date.
+func parseRetryAfter(header string) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return time.Duration(max(seconds, 0)) * time.Second, true
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
+
+// Makes seekable request bodies rewindable, so the request can be retried.
+// Bodies created from byte slices and strings are already rewindable.
+func setGetBody(request *http.Request, body io.Reader) {
+	if request.GetBody != nil || body == nil {
+		return
+	}
+	seeker, ok := body.(io.ReadSeeker)
+	if !ok {
+		return
+	}
+	start, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return
+	}
+	request.GetBody = func() (io.ReadCloser, error) {
+		_, err := seeker.Seek(start, io.SeekStart)
+		return io.NopCloser(seeker), err
+	}
+}
@@ -0,0 +1,67 @@
+package gonedrive
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	tests := []struct {
+		attempt int
+		delay   time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond},
+		{4, 800 * time.Millisecond},
+		{5, time.Second},
+		{100, time.Second},
+	}
+	for _, test := range tests {
+		for range 100 {
+			got := policy.backoff(test.attempt)
+			if got < test.delay/2 || got > test.delay {
+				t.Fatalf("attempt %d: got %v, want between %v and %v", test.attempt, got, test.delay/2, test.delay)
+			}
+		}
+	}
+}
+
+func TestBackoffNoDelay(t *testing.T) {
+	policy := RetryPolicy{}
+	for attempt := 1; attempt <= 5; attempt++ {
+		if got := policy.backoff(attempt); got != 0 {
+			t.Errorf("attempt %d: got %v, want 0", attempt, got)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		header string
+		delay  time.Duration
+		ok     bool
+	}{
+		{"", 0, false},
+		{"0", 0, true},
+		{"5", 5 * time.Second, true},
+		{"-5", 0, true},
+		{"soon", 0, false},
+		{"Mon, 02 Jan 2006 15:04:05 GMT", 0, true},
+	}
+	for _, test := range tests {
+		delay, ok := parseRetryAfter(test.header)
+		if delay != test.delay || ok != test.ok {
+			t.Errorf("parseRetryAfter(%q) = %v, %v; want %v, %v", test.header, delay, ok, test.delay, test.ok)
+		}
+	}
+
+	// A date in the future
+	date := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	delay, ok := parseRetryAfter(date)
+	if !ok || delay <= 55*time.Second || delay > time.Minute {
+		t.Errorf("parseRetryAfter(%q) = %v, %v; want about a minute", date, delay, ok)
+	}
+}
@@ -1,6 +1,7 @@
 package gonedrive
 
 import (
+	"context"
 	"errors"
 	"io"
 	"os"
@@ -28,50 +29,56 @@ type SyncFile struct {
 type SyncFilterFn func(file SyncFile) bool
 
 type syncContext struct {
-	t          *GraphToken
+	ctx        context.Context
+	client     *Client
 	mux        sync.Mutex
 	wg         sync.WaitGroup
 	c          chan *DriveItem
 	localFiles map[string]SyncFile
 	filterFn   SyncFilterFn
 	eventFn    SyncEventFn
-	remotePath string
+	remote     ItemRef
 	localPath  string
 }
 
-func (ctx *syncContext) addItem(item *DriveItem) {
-	ctx.wg.Add(1)
-	ctx.c <- item
+func (job *syncContext) addItem(item *DriveItem) {
+	job.wg.Add(1)
+	job.c <- item
 }
 
-func (ctx *syncContext) syncQueue() {
-	for item := range ctx.c {
-		ctx.handleItem(item)
+func (job *syncContext) syncQueue() {
+	for item := range job.c {
+		job.handleItem(item)
 	}
 }
 
-func (ctx *syncContext) sendEvent(event SyncEvent) {
-	if ctx.eventFn != nil {
-		ctx.eventFn(event)
+func (job *syncContext) sendEvent(event SyncEvent) {
+	if job.eventFn != nil {
+		job.eventFn(event)
 	}
 }
 
-func (ctx *syncContext) handleItem(item *DriveItem) {
-	defer ctx.wg.Done()
+func (job *syncContext) handleItem(item *DriveItem) {
+	defer job.wg.Done()
+
+	// Sync cancelled, drain the queue
+	if job.ctx.Err() != nil {
+		return
+	}
 
 	// Find local file in map
-	ctx.mux.Lock()
-	localFile, exists := ctx.localFiles[item.Name]
-	delete(ctx.localFiles, item.Name)
-	ctx.mux.Unlock()
+	job.mux.Lock()
+	localFile, exists := job.localFiles[item.Name]
+	delete(job.localFiles, item.Name)
+	job.mux.Unlock()
 
 	// I'll be using these
-	remotePath := path.Join(ctx.remotePath, item.Name)
-	localPath := filepath.Join(ctx.localPath, item.Name)
+	remotePath := path.Join(job.remote.String(), item.Name)
+	localPath := filepath.Join(job.localPath, item.Name)
 
 	// Cannot sync directories at the moment
 	if item.IsDir() {
-		ctx.sendEvent(&SyncEventError{
+		job.sendEvent(&SyncEventError{
 			LocalPath:  localPath,
 			RemotePath: remotePath,
 			Err:        ErrSyncRemoteDirectory,
@@ -83,7 +90,7 @@ func (ctx *syncContext) handleItem(item *DriveItem) {
 	if exists {
 		// Cannot sync directories
 		if localFile.IsDir {
-			ctx.sendEvent(&SyncEventError{
+			job.sendEvent(&SyncEventError{
 				LocalPath:  localPath,
 				RemotePath: remotePath,
 				Err:        ErrSyncLocalDirectory,
@@ -92,8 +99,8 @@ func (ctx *syncContext) handleItem(item *DriveItem) {
 		}
 
 		// Is local file identical?
-		if ctx.syncFilesIdentical(localFile, item) {
-			ctx.sendEvent(&SyncEventSkip{
+		if job.syncFilesIdentical(localFile, item) {
+			job.sendEvent(&SyncEventSkip{
 				LocalPath:  localPath,
 				RemotePath: remotePath,
 			})
@@ -102,7 +109,7 @@ func (ctx *syncContext) handleItem(item *DriveItem) {
 
 		// No they are not, remove local file
 		if err := os.Remove(localPath); err != nil {
-			ctx.sendEvent(&SyncEventError{
+			job.sendEvent(&SyncEventError{
 				LocalPath:  localPath,
 				RemotePath: remotePath,
 				Err:        err,
@@ -112,7 +119,7 @@ func (ctx *syncContext) handleItem(item *DriveItem) {
 	}
 
 	// Send begin event
-	ctx.sendEvent(&SyncEventBegin{
+	job.sendEvent(&SyncEventBegin{
 		LocalPath:  localPath,
 		RemotePath: remotePath,
 	})
@@ -120,7 +127,7 @@ func (ctx *syncContext) handleItem(item *DriveItem) {
 	// Prepare end event
 	success := false
 	defer func() {
-		ctx.sendEvent(&SyncEventEnd{
+		job.sendEvent(&SyncEventEnd{
 			LocalPath:  localPath,
 			RemotePath: remotePath,
 			IsUpload:   false,
@@ -131,7 +138,7 @@ func (ctx *syncContext) handleItem(item *DriveItem) {
 	// Create writer for local file
 	localWriter, err := os.Create(localPath)
 	if err != nil {
-		ctx.sendEvent(&SyncEventError{
+		job.sendEvent(&SyncEventError{
 			LocalPath:  localPath,
 			RemotePath: remotePath,
 			Err:        err,
@@ -141,20 +148,25 @@ func (ctx *syncContext) handleItem(item *DriveItem) {
 	defer localWriter.Close()
 
 	// Get reader for drive file
-	remoteReader, err := ctx.t.DownloadDriveItem(item)
+	remoteReader, err := job.client.DownloadDriveItem(job.ctx, ItemByID(item.Id).OnDrive(job.remote.Drive))
 	if err != nil {
-		ctx.sendEvent(&SyncEventError{
+		job.sendEvent(&SyncEventError{
 			LocalPath:  localPath,
 			RemotePath: remotePath,
 			Err:        err,
 		})
 		return
 	}
+	if job.client.syncVerify {
+		remoteReader = NewVerifyingReader(remoteReader, item)
+	}
 	defer remoteReader.Close()
 
 	// Write remote contents to local file
 	if _, err = io.Copy(localWriter, remoteReader); err != nil {
-		ctx.sendEvent(&SyncEventError{
+		localWriter.Close()
+		os.Remove(localPath)
+		job.sendEvent(&SyncEventError{
 			LocalPath:  localPath,
 			RemotePath: remotePath,
 			Err:        err,
@@ -166,7 +178,7 @@ func (ctx *syncContext) handleItem(item *DriveItem) {
 	success = true
 }
 
-func (ctx *syncContext) syncFilesIdentical(local SyncFile, remote *DriveItem) bool {
+func (job *syncContext) syncFilesIdentical(local SyncFile, remote *DriveItem) bool {
 	if local.Size != remote.Size {
 		return false
 	}
@@ -187,7 +199,16 @@ func (ctx *syncContext) syncFilesIdentical(local SyncFile, remote *DriveItem) bo
 // Downloads files that don't exist in local directory.
 // Deletes files in local directory not found on OneDrive.
 // Does not redownload existing (up-to-date) files.
-func (t *GraphToken) SyncFolder(remotePath string, localPath string, filterFn SyncFilterFn, eventFn SyncEventFn) error {
+//
+// The remote folder can be given by path or by ID, in any drive.
+// In events, remote paths are relative to the string form of remote.
+//
+// If ctx is cancelled, in-flight downloads are aborted, no more downloads are
+// started, no local files are deleted, and the context error is returned.
+//
+// Downloaded files are only checked against the API if the client was made
+// with WithSyncVerify.
+func (c *Client) SyncFolder(ctx context.Context, remote ItemRef, localPath string, filterFn SyncFilterFn, eventFn SyncEventFn) error {
 	// Create local output directory
 	if err := os.MkdirAll(localPath, os.ModePerm); err != nil {
 		return err
@@ -213,27 +234,34 @@ func (t *GraphToken) SyncFolder(remotePath string, localPath string, filterFn Sy
 	}
 
 	// Initialize sync job
-	ctx := syncContext{
-		remotePath: remotePath,
+	job := syncContext{
+		ctx:        ctx,
+		client:     c,
+		remote:     remote,
 		localPath:  localPath,
 		localFiles: localFiles,
 		filterFn:   filterFn,
 		eventFn:    eventFn,
 		c:          make(chan *DriveItem, 32),
-		t:          t,
 	}
 	for i := 0; i < 5; i++ {
-		go ctx.syncQueue()
+		go job.syncQueue()
 	}
+	defer close(job.c)
 
 	// Get OneDrive files
-	onlineList, err := t.ListFolder(remotePath)
+	query := NewQuery().Select("id", "name", "size", "file", "folder")
+	onlineList, err := c.ListFolder(ctx, remote, query)
 	if err != nil {
 		return err
 	}
 	for _, item := range onlineList {
+		if ctx.Err() != nil {
+			break
+		}
+
 		syncFile := SyncFile{
-			FileName: path.Join(remotePath, item.Name),
+			FileName: path.Join(remote.String(), item.Name),
 			IsDir:    item.IsDir(),
 			Size:     int64(item.Size),
 		}
@@ -242,11 +270,14 @@ func (t *GraphToken) SyncFolder(remotePath string, localPath string, filterFn Sy
 		}
 
 		// Do the thing
-		ctx.addItem(item)
+		job.addItem(item)
 	}
 
 	// Wait for downloads to complete
-	ctx.wg.Wait()
+	job.wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 
 	// Remove remaining files in folder
 	for _, localFile := range localFiles {
@@ -257,12 +288,12 @@ func (t *GraphToken) SyncFolder(remotePath string, localPath string, filterFn Sy
 		// Remove file
 		err := os.Remove(localFile.FileName)
 		if err != nil {
-			ctx.sendEvent(&SyncEventError{
+			job.sendEvent(&SyncEventError{
 				LocalPath: localFile.FileName,
 				Err:       err,
 			})
 		} else {
-			ctx.sendEvent(SyncEventDelete{
+			job.sendEvent(SyncEventDelete{
 				LocalPath: localFile.FileName,
 			})
 		}
@@ -271,3 +302,19 @@ func (t *GraphToken) SyncFolder(remotePath string, localPath string, filterFn Sy
 	// All good
 	return nil
 }
+
+// Same as Client.SyncFolder.
+func (t *GraphToken) SyncFolder(remotePath string, localPath string, filterFn SyncFilterFn, eventFn SyncEventFn) error {
+	return t.SyncFolderContext(context.Background(), remotePath, localPath, filterFn, eventFn)
+}
+
+// Same as Client.SyncFolder.
+func (t *GraphToken) SyncFolderContext(ctx context.Context, remotePath string, localPath string, filterFn SyncFilterFn, eventFn SyncEventFn) error {
+	return t.client().SyncFolder(ctx, ItemByPath(remotePath), localPath, filterFn, eventFn)
+}
+
+// Same as SyncFolderContext, but downloaded files are checked against the
+// size and QuickXorHash reported by the API, like a Client made with WithSyncVerify.
+func (t *GraphToken) SyncFolderVerify(ctx context.Context, remotePath string, localPath string, filterFn SyncFilterFn, eventFn SyncEventFn) error {
+	return t.client(WithSyncVerify(true)).SyncFolder(ctx, ItemByPath(remotePath), localPath, filterFn, eventFn)
+}
@@ -0,0 +1,80 @@
+package gonedrive
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Access tokens are refreshed this long before they expire,
+// so they don't run out while a request is in flight.
+const tokenExpiryDelta = 5 * time.Minute
+
+// Reports whether the access token has expired, or is about to.
+// Tokens without a known expiry are assumed to be valid.
+func (t *GraphToken) expired() bool {
+	if t.AccessToken == "" {
+		return true
+	}
+	if t.ExpiresAt.IsZero() {
+		return false
+	}
+	return time.Now().Add(tokenExpiryDelta).After(t.ExpiresAt)
+}
+
+// Returns a valid access token, refreshing it first if needed.
+// Safe to call from multiple goroutines, only one of them will refresh.
+func (t *GraphToken) Token(ctx context.Context) (string, error) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if t.expired() {
+		if err := t.refreshAndSave(ctx); err != nil {
+			return "", err
+		}
+	}
+	return t.AccessToken, nil
+}
+
+// Marks the access token as rejected by the API, so the next call to Token refreshes it.
+// If the token has already been replaced, this does nothing.
+func (t *GraphToken) Invalidate(token string) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if t.AccessToken == token {
+		t.AccessToken = ""
+	}
+}
+
+// Sets the HTTP client used to obtain and refresh tokens.
+// Defaults to the client given to NewClient with WithHTTPClient,
+// otherwise http.DefaultClient.
+func (t *GraphToken) SetHTTPClient(client *http.Client) {
+	t.httpClient = client
+}
+
+// Fills in the HTTP client and User-Agent used for token requests,
+// unless they have been set already.
+func (t *GraphToken) setClientDefaults(client *http.Client, userAgent string) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	if t.httpClient == nil {
+		t.httpClient = client
+	}
+	if t.userAgent == "" {
+		t.userAgent = userAgent
+	}
+}
+
+func (t *GraphToken) getHTTPClient() *http.Client {
+	if t.httpClient == nil {
+		return http.DefaultClient
+	}
+	return t.httpClient
+}
+
+func (t *GraphToken) refreshAndSave(ctx context.Context) error {
+	if err := t.refresh(ctx); err != nil {
+		return err
+	}
+	return t.save()
+}
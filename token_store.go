@@ -0,0 +1,167 @@
+package gonedrive
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Returned by TokenStore.Load when no token has been saved yet.
+var ErrNoToken = errors.New("no token in store")
+
+// Persistent storage for a GraphToken.
+// Once a store is attached to a token, the token saves itself to the store
+// whenever a new access token is obtained or refreshed.
+type TokenStore interface {
+	// Loads the saved token.
+	// Returns ErrNoToken if nothing has been saved yet.
+	Load() (*GraphToken, error)
+
+	// Saves the token, replacing whatever was stored before.
+	Save(t *GraphToken) error
+}
+
+// Loads a token from the given store, and attaches the store to it.
+// If the store is empty, an empty token is returned instead,
+// which can then be passed to CreateAccess.
+func LoadToken(store TokenStore) (*GraphToken, error) {
+	t, err := store.Load()
+	if errors.Is(err, ErrNoToken) {
+		t, err = &GraphToken{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	t.SetStore(store)
+	return t, nil
+}
+
+// Attaches a store to the token.
+// The token is saved to it every time it is obtained or refreshed.
+func (t *GraphToken) SetStore(store TokenStore) {
+	t.store = store
+}
+
+// Saves the token to its store, if it has one.
+func (t *GraphToken) save() error {
+	if t.store == nil {
+		return nil
+	}
+	return t.store.Save(t)
+}
+
+// Stores the token as a JSON file.
+type FileTokenStore struct {
+	Path string
+}
+
+func NewFileTokenStore(path string) *FileTokenStore {
+	return &FileTokenStore{Path: path}
+}
+
+func (s *FileTokenStore) Load() (*GraphToken, error) {
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNoToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	t := &GraphToken{}
+	err = json.Unmarshal(data, t)
+	return t, err
+}
+
+// Writes to a temporary file first, so a crash can't leave a broken token behind.
+func (s *FileTokenStore) Save(t *GraphToken) error {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+
+	// Write temporary file
+	f, err := os.CreateTemp(filepath.Dir(s.Path), filepath.Base(s.Path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	// Replace token file
+	return os.Rename(f.Name(), s.Path)
+}
+
+// Keeps the token in memory.
+// Mostly useful for tests, and for programs that handle persistence themselves.
+type MemoryTokenStore struct {
+	mux  sync.Mutex
+	data []byte
+}
+
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{}
+}
+
+func (s *MemoryTokenStore) Load() (*GraphToken, error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if s.data == nil {
+		return nil, ErrNoToken
+	}
+
+	t := &GraphToken{}
+	err := json.Unmarshal(s.data, t)
+	return t, err
+}
+
+func (s *MemoryTokenStore) Save(t *GraphToken) error {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+
+	s.mux.Lock()
+	s.data = data
+	s.mux.Unlock()
+	return nil
+}
+
+// Reads the token as JSON from an environment variable.
+// Saved tokens are written back to the variable, so they are only visible
+// to this process and its children.
+type EnvTokenStore struct {
+	Name string
+}
+
+func NewEnvTokenStore(name string) *EnvTokenStore {
+	return &EnvTokenStore{Name: name}
+}
+
+func (s *EnvTokenStore) Load() (*GraphToken, error) {
+	data, ok := os.LookupEnv(s.Name)
+	if !ok || data == "" {
+		return nil, ErrNoToken
+	}
+
+	t := &GraphToken{}
+	err := json.Unmarshal([]byte(data), t)
+	return t, err
+}
+
+func (s *EnvTokenStore) Save(t *GraphToken) error {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	return os.Setenv(s.Name, string(data))
+}
@@ -1,16 +1,29 @@
 package gonedrive
 
-import "fmt"
+import (
+	"net/http"
+	"sync"
+	"time"
+)
 
 type GraphToken struct {
-	TokenType    string `json:"token_type"`
-	ExpiresIn    int    `json:"expires_in"`
-	Scope        string `json:"scope"`
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
-
-	clientID    string
-	redirectURI string
+	TokenType    string    `json:"token_type"`
+	ExpiresIn    int       `json:"expires_in"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	Scope        string    `json:"scope"`
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+
+	// Saved along with the token, so it can be refreshed later.
+	ClientID    string `json:"client_id"`
+	RedirectURI string `json:"redirect_uri"`
+	TokenConfig
+
+	store      TokenStore
+	credential clientCredential
+	httpClient *http.Client
+	userAgent  string
+	mux        sync.Mutex
 }
 
 type DriveItem struct {
@@ -24,8 +37,9 @@ type DriveItem struct {
 	CreationDate string `json:"createdDateTime"`
 	ModifiedDate string `json:"lastModifiedDateTime"`
 
-	Root        *struct{} `json:"root"`
-	DownloadURL string    `json:"@content.downloadUrl"`
+	Root            *struct{}      `json:"root"`
+	ParentReference *ItemReference `json:"parentReference"`
+	DownloadURL     string         `json:"@content.downloadUrl"`
 
 	Audio *struct {
 		Album             string `json:"album"`
@@ -65,6 +79,16 @@ func (item *DriveItem) IsDir() bool {
 	return item.Folder != nil
 }
 
+// Where an item is, as returned by the API.
+type ItemReference struct {
+	DriveId   string `json:"driveId"`
+	DriveType string `json:"driveType"`
+	Id        string `json:"id"`
+	Name      string `json:"name"`
+	Path      string `json:"path"`
+	SiteId    string `json:"siteId"`
+}
+
 type Hashes struct {
 	Crc32    string `json:"crc32Hash"`
 	Sha1     string `json:"sha1Hash"`
@@ -72,33 +96,12 @@ type Hashes struct {
 	QuickXor string `json:"quickXorHash"`
 }
 
-type ErrorResponse struct {
-	Outer struct {
-		Code    string `json:"code"`
-		Message string `json:"message"`
-		Inner   struct {
-			Date            string `json:"date"`
-			RequestID       string `json:"request-id"`
-			ClientRequestId string `json:"client-request-id"`
-		} `json:"innerError"`
-	} `json:"error"`
-}
-
-func (err *ErrorResponse) Error() string {
-	return fmt.Sprintf(
-		"%s: %s\n%s, %s",
-		err.Outer.Code,
-		err.Outer.Message,
-		err.Outer.Inner.Date,
-		err.Outer.Inner.RequestID,
-	)
-}
-
 type ResponsePaginated[T any] struct {
-	Context  string `json:"@odata.context"`
-	Count    int    `json:"@odata.count"`
-	NextLink string `json:"@odata.nextLink"`
-	Value    T      `json:"value"`
+	Context   string `json:"@odata.context"`
+	Count     int    `json:"@odata.count"`
+	NextLink  string `json:"@odata.nextLink"`
+	DeltaLink string `json:"@odata.deltaLink"`
+	Value     T      `json:"value"`
 }
 
 type ConflictBehaviour string
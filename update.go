@@ -0,0 +1,125 @@
+package gonedrive
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/url"
+	"time"
+)
+
+// Changes made by UpdateItem.
+// Only the fields that are set are changed.
+type UpdateItemParams struct {
+	// New name of the item.
+	Name string
+
+	// ID of the folder to move the item to.
+	// Items cannot be moved between drives, see CopyItem for that.
+	ParentID string
+
+	// New description of the item.
+	Description *string
+
+	// New timestamps, as reported by the file system the item came from.
+	CreatedAt  *time.Time
+	ModifiedAt *time.Time
+
+	// What to do if the new name is taken.
+	ConflictBehaviour ConflictBehaviour
+
+	// If set, the item is only updated if its eTag still matches,
+	// otherwise the error matches ErrPreconditionFailed.
+	IfMatch string
+}
+
+func (p *UpdateItemParams) MarshalJSON() ([]byte, error) {
+	out := map[string]any{}
+	if p.Name != "" {
+		out["name"] = p.Name
+	}
+	if p.ParentID != "" {
+		out["parentReference"] = map[string]any{"id": p.ParentID}
+	}
+	if p.Description != nil {
+		out["description"] = *p.Description
+	}
+
+	if p.CreatedAt != nil || p.ModifiedAt != nil {
+		fileInfo := map[string]string{}
+		if p.CreatedAt != nil {
+			fileInfo["createdDateTime"] = p.CreatedAt.Format(time.RFC3339)
+		}
+		if p.ModifiedAt != nil {
+			fileInfo["lastModifiedDateTime"] = p.ModifiedAt.Format(time.RFC3339)
+		}
+		out["fileSystemInfo"] = fileInfo
+	}
+
+	// Final output
+	return json.Marshal(out)
+}
+
+// Updates the name, location, description or timestamps of an item.
+// Nothing is uploaded, so this is cheap even for large files.
+func (c *Client) UpdateItem(ctx context.Context, item ItemRef, params UpdateItemParams) (*DriveItem, error) {
+	if params.Name != "" {
+		if err := ValidateName(params.Name); err != nil {
+			return nil, err
+		}
+	}
+	endpoint, err := c.itemEndpoint(item, "")
+	if err != nil {
+		return nil, err
+	}
+	if params.ConflictBehaviour != "" {
+		endpoint += "?" + url.QueryEscape("@microsoft.graph.conflictBehavior") + "=" + url.QueryEscape(string(params.ConflictBehaviour))
+	}
+
+	// Create request body
+	requestData, _ := params.MarshalJSON()
+	request, err := c.BuildRequestContext(ctx, "PATCH", endpoint, bytes.NewReader(requestData))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if params.IfMatch != "" {
+		request.Header.Set("If-Match", params.IfMatch)
+	}
+	return SendRequest[DriveItem](c, request)
+}
+
+// Renames an item, keeping it in the same folder.
+func (c *Client) RenameItem(ctx context.Context, item ItemRef, name string, conflictBehaviour ConflictBehaviour) (*DriveItem, error) {
+	return c.UpdateItem(ctx, item, UpdateItemParams{
+		Name:              name,
+		ConflictBehaviour: conflictBehaviour,
+	})
+}
+
+// Moves an item into another folder in the same drive.
+// If newName is not empty, the item is renamed as well.
+// A parent given by path is looked up first, to find its ID.
+func (c *Client) MoveItem(ctx context.Context, item ItemRef, parent ItemRef, newName string, conflictBehaviour ConflictBehaviour) (*DriveItem, error) {
+	parentID, err := c.itemID(ctx, parent)
+	if err != nil {
+		return nil, err
+	}
+	return c.UpdateItem(ctx, item, UpdateItemParams{
+		Name:              newName,
+		ParentID:          parentID,
+		ConflictBehaviour: conflictBehaviour,
+	})
+}
+
+// Finds the ID of an item, looking it up if needed.
+func (c *Client) itemID(ctx context.Context, ref ItemRef) (string, error) {
+	if ref.ID != "" && len(splitPath(ref.Path)) == 0 {
+		return ref.ID, nil
+	}
+	item, err := c.GetDriveItem(ctx, ref, NewQuery().Select("id"))
+	if err != nil {
+		return "", err
+	}
+	return item.Id, nil
+}
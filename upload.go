@@ -2,6 +2,7 @@ package gonedrive
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -10,9 +11,12 @@ import (
 	"time"
 )
 
-func (t *GraphToken) UploadContent(r io.Reader, size int64, destPath string, params UploadSessionParams) (*DriveItem, error) {
+// Uploads size bytes from r to dest, using an upload session.
+// dest is usually a path to a new file, or the ID of a file to overwrite.
+// If ctx is cancelled, the upload stops before the next chunk is sent.
+func (c *Client) UploadContent(ctx context.Context, r io.Reader, size int64, dest ItemRef, params UploadSessionParams) (*DriveItem, error) {
 	// Get upload session
-	session, err := t.CreateUploadSession(destPath, params)
+	session, err := c.CreateUploadSession(ctx, dest, params)
 	if err != nil {
 		return nil, err
 	}
@@ -22,6 +26,9 @@ func (t *GraphToken) UploadContent(r io.Reader, size int64, destPath string, par
 	buf := make([]byte, 0x100000)
 	pos := int64(0)
 	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		n, err := io.ReadFull(r, buf[:min(int64(len(buf)), size-pos)])
 		isEof := pos+int64(n) == size
 		if err != nil && (!isEof && (err != io.EOF || err != io.ErrUnexpectedEOF)) {
@@ -30,7 +37,7 @@ func (t *GraphToken) UploadContent(r io.Reader, size int64, destPath string, par
 
 		// Create request
 		r := bytes.NewReader(buf[:n])
-		request, err := http.NewRequest("PUT", uploadUrl, r)
+		request, err := http.NewRequestWithContext(ctx, "PUT", uploadUrl, r)
 		if err != nil {
 			return nil, err
 		}
@@ -47,17 +54,18 @@ func (t *GraphToken) UploadContent(r io.Reader, size int64, destPath string, par
 
 		// Send
 		if !isEof {
-			_, err := t.SendRequest(request)
+			response, err := c.SendRequest(request)
 			if err != nil {
 				return nil, err
 			}
+			response.Body.Close()
 		} else {
-			return SendRequest[DriveItem](t, request)
+			return SendRequest[DriveItem](c, request)
 		}
 	}
 }
 
-func (t *GraphToken) UploadFile(file fs.File, destPath string, conflictBehaviour ConflictBehaviour) (*DriveItem, error) {
+func (c *Client) UploadFile(ctx context.Context, file fs.File, dest ItemRef, conflictBehaviour ConflictBehaviour) (*DriveItem, error) {
 	stat, err := file.Stat()
 	if err != nil {
 		return nil, err
@@ -71,7 +79,7 @@ func (t *GraphToken) UploadFile(file fs.File, destPath string, conflictBehaviour
 	}
 
 	totalSize := stat.Size()
-	return t.UploadContent(file, totalSize, destPath, params)
+	return c.UploadContent(ctx, file, totalSize, dest, params)
 }
 
 type UploadSessionParams struct {
@@ -105,14 +113,18 @@ func (p *UploadSessionParams) MarshalJSON() ([]byte, error) {
 	return json.Marshal(map[string]any{"item": out})
 }
 
-func (t *GraphToken) CreateUploadSession(destPath string, params UploadSessionParams) (*UploadSessionResponse, error) {
+func (c *Client) CreateUploadSession(ctx context.Context, dest ItemRef, params UploadSessionParams) (*UploadSessionResponse, error) {
+	endpoint, err := c.itemEndpoint(dest, "createUploadSession")
+	if err != nil {
+		return nil, err
+	}
+
 	// Create request body
 	requestData, _ := params.MarshalJSON()
 	requestBody := bytes.NewReader(requestData)
 
 	// Create upload session
-	urlPath := EndpointPath(destPath, "createUploadSession")
-	response, err := t.MakeRequest("POST", "/me/drive/"+urlPath, requestBody, "application/json")
+	response, err := c.MakeRequestContext(ctx, "POST", endpoint, requestBody, "application/json")
 	if err != nil {
 		return nil, err
 	}
@@ -129,3 +141,33 @@ func (t *GraphToken) CreateUploadSession(destPath string, params UploadSessionPa
 	err = json.Unmarshal(responseBody, dec)
 	return dec, err
 }
+
+// Same as Client.UploadContent.
+func (t *GraphToken) UploadContent(r io.Reader, size int64, destPath string, params UploadSessionParams) (*DriveItem, error) {
+	return t.UploadContentContext(context.Background(), r, size, destPath, params)
+}
+
+// Same as Client.UploadContent.
+func (t *GraphToken) UploadContentContext(ctx context.Context, r io.Reader, size int64, destPath string, params UploadSessionParams) (*DriveItem, error) {
+	return t.client().UploadContent(ctx, r, size, ItemByPath(destPath), params)
+}
+
+// Same as Client.UploadFile.
+func (t *GraphToken) UploadFile(file fs.File, destPath string, conflictBehaviour ConflictBehaviour) (*DriveItem, error) {
+	return t.UploadFileContext(context.Background(), file, destPath, conflictBehaviour)
+}
+
+// Same as Client.UploadFile.
+func (t *GraphToken) UploadFileContext(ctx context.Context, file fs.File, destPath string, conflictBehaviour ConflictBehaviour) (*DriveItem, error) {
+	return t.client().UploadFile(ctx, file, ItemByPath(destPath), conflictBehaviour)
+}
+
+// Same as Client.CreateUploadSession.
+func (t *GraphToken) CreateUploadSession(destPath string, params UploadSessionParams) (*UploadSessionResponse, error) {
+	return t.CreateUploadSessionContext(context.Background(), destPath, params)
+}
+
+// Same as Client.CreateUploadSession.
+func (t *GraphToken) CreateUploadSessionContext(ctx context.Context, destPath string, params UploadSessionParams) (*UploadSessionResponse, error) {
+	return t.client().CreateUploadSession(ctx, ItemByPath(destPath), params)
+}